@@ -0,0 +1,240 @@
+// Package cache keeps recently resolved short links in memory in front of
+// another URLRepository.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+)
+
+// Stats counts lookups since the cache was created.
+type Stats struct {
+	Hits int64
+	// NegativeHits are lookups answered from a cached ErrNotFound.
+	NegativeHits int64
+	Misses       int64
+	Evictions    int64
+	Entries      int
+}
+
+// Repository caches FindByToken results, including unknown tokens, and
+// passes everything else through. Writes made through it invalidate what
+// they touch; writes made by other replicas become visible once the
+// affected entry's TTL runs out.
+type Repository struct {
+	next        repository.URLRepository
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation changes on every invalidation so a lookup that raced with
+	// a write does not cache what it read before the write.
+	generation uint64
+	stats      Stats
+}
+
+var _ repository.URLRepository = (*Repository)(nil)
+
+type entry struct {
+	token string
+	// shortURL is nil for a cached miss.
+	shortURL *entity.ShortURL
+	expires  time.Time
+}
+
+// Option configures a Repository.
+type Option func(*Repository)
+
+// WithSize bounds the number of cached tokens, found or not.
+func WithSize(size int) Option {
+	return func(r *Repository) {
+		r.size = size
+	}
+}
+
+func WithTTL(ttl time.Duration) Option {
+	return func(r *Repository) {
+		r.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long an unknown token is remembered. Keep it
+// short when several replicas share a database, since a link created on
+// another replica stays unknown here until then.
+func WithNegativeTTL(ttl time.Duration) Option {
+	return func(r *Repository) {
+		r.negativeTTL = ttl
+	}
+}
+
+func NewRepository(next repository.URLRepository, opts ...Option) *Repository {
+	r := &Repository{
+		next:        next,
+		size:        10000,
+		ttl:         5 * time.Minute,
+		negativeTTL: 30 * time.Second,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Repository) FindByToken(ctx context.Context, token string) (*entity.ShortURL, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	if elem, ok := r.entries[token]; ok {
+		e := elem.Value.(*entry)
+		if now.Before(e.expires) {
+			r.lru.MoveToFront(elem)
+			if e.shortURL == nil {
+				r.stats.NegativeHits++
+				r.mu.Unlock()
+				return nil, repository.ErrNotFound
+			}
+			r.stats.Hits++
+			r.mu.Unlock()
+			return clone(e.shortURL), nil
+		}
+		r.remove(elem)
+	}
+	r.stats.Misses++
+	generation := r.generation
+	r.mu.Unlock()
+
+	shortURL, err := r.next.FindByToken(ctx, token)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		r.put(generation, &entry{token: token, expires: now.Add(r.negativeTTL)})
+	case err == nil && shortURL.MaxDownloads == 0:
+		// Download counters change on every download, possibly on another
+		// replica, so limited links are always read fresh.
+		expires := now.Add(r.ttl)
+		if !shortURL.ExpiresAt.IsZero() && shortURL.ExpiresAt.Before(expires) {
+			expires = shortURL.ExpiresAt
+		}
+		r.put(generation, &entry{token: token, shortURL: clone(shortURL), expires: expires})
+	}
+	return shortURL, err
+}
+
+// clone keeps callers, which may update fields such as Downloads, from
+// changing the cached copy.
+func clone(shortURL *entity.ShortURL) *entity.ShortURL {
+	c := *shortURL
+	return &c
+}
+
+func (r *Repository) put(generation uint64, e *entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation || r.size <= 0 {
+		return
+	}
+	if elem, ok := r.entries[e.token]; ok {
+		r.remove(elem)
+	}
+	r.entries[e.token] = r.lru.PushFront(e)
+	for r.lru.Len() > r.size {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+}
+
+// remove must be called with mu held.
+func (r *Repository) remove(elem *list.Element) {
+	delete(r.entries, elem.Value.(*entry).token)
+	r.lru.Remove(elem)
+}
+
+func (r *Repository) invalidate(tokens ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for _, token := range tokens {
+		if elem, ok := r.entries[token]; ok {
+			r.remove(elem)
+		}
+	}
+}
+
+func (r *Repository) invalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	clear(r.entries)
+	r.lru.Init()
+}
+
+// CacheStats returns a snapshot of the lookup counters.
+func (r *Repository) CacheStats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Entries = r.lru.Len()
+	return stats
+}
+
+func (r *Repository) Save(ctx context.Context, shortURL *entity.ShortURL) error {
+	// A token that was probed before it existed is cached as unknown.
+	defer r.invalidate(shortURL.Token)
+	return r.next.Save(ctx, shortURL)
+}
+
+func (r *Repository) SaveAll(ctx context.Context, shortURLs []*entity.ShortURL) error {
+	tokens := make([]string, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		tokens[i] = shortURL.Token
+	}
+	defer r.invalidate(tokens...)
+	return r.next.SaveAll(ctx, shortURLs)
+}
+
+func (r *Repository) ConsumeDownload(ctx context.Context, token string) error {
+	defer r.invalidate(token)
+	return r.next.ConsumeDownload(ctx, token)
+}
+
+func (r *Repository) ReleaseDownload(ctx context.Context, token string) error {
+	defer r.invalidate(token)
+	return r.next.ReleaseDownload(ctx, token)
+}
+
+func (r *Repository) Delete(ctx context.Context, token string) error {
+	defer r.invalidate(token)
+	return r.next.Delete(ctx, token)
+}
+
+// DeleteExpired drops the whole cache when anything was purged, since the
+// purged tokens are not known here.
+func (r *Repository) DeleteExpired(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+	deleted, err := r.next.DeleteExpired(ctx, createdBefore, now)
+	if deleted > 0 {
+		r.invalidateAll()
+	}
+	return deleted, err
+}
+
+func (r *Repository) RecordHits(ctx context.Context, hits []entity.Hit) error {
+	return r.next.RecordHits(ctx, hits)
+}
+
+func (r *Repository) Stats(ctx context.Context, token string) (*entity.LinkStats, error) {
+	return r.next.Stats(ctx, token)
+}
@@ -0,0 +1,191 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"transfer-shortener/adapter/cache"
+	"transfer-shortener/adapter/sqlite"
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/domain/repository/repositorytest"
+)
+
+// countingRepository counts the lookups that reach the database.
+type countingRepository struct {
+	repository.URLRepository
+	finds atomic.Int64
+}
+
+func (r *countingRepository) FindByToken(ctx context.Context, token string) (*entity.ShortURL, error) {
+	r.finds.Add(1)
+	return r.URLRepository.FindByToken(ctx, token)
+}
+
+func newBackingRepository(t *testing.T) *countingRepository {
+	repo, err := sqlite.NewRepository(filepath.Join(t.TempDir(), "shortener.db"))
+	if err != nil {
+		t.Fatalf("open repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return &countingRepository{URLRepository: repo}
+}
+
+func TestRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.URLRepository {
+		return cache.NewRepository(newBackingRepository(t))
+	})
+}
+
+func TestRepository_CachesHitsAndMisses(t *testing.T) {
+	backing := newBackingRepository(t)
+	repo := cache.NewRepository(backing)
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &entity.ShortURL{Token: "abcd", FullURL: "https://example.com/x/a.txt", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if _, err := repo.FindByToken(ctx, "abcd"); err != nil {
+			t.Fatalf("FindByToken: %v", err)
+		}
+		if _, err := repo.FindByToken(ctx, "abc12"); !errors.Is(err, repository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+
+	if got := backing.finds.Load(); got != 2 {
+		t.Errorf("expected 2 database lookups, got %d", got)
+	}
+	stats := repo.CacheStats()
+	if stats.Hits != 2 || stats.NegativeHits != 2 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRepository_ReturnsCopies(t *testing.T) {
+	repo := cache.NewRepository(newBackingRepository(t))
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &entity.ShortURL{Token: "abcd", FullURL: "https://example.com/x/a.txt", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	first, _ := repo.FindByToken(ctx, "abcd")
+	first.FullURL = "https://evil.example/"
+
+	second, err := repo.FindByToken(ctx, "abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.FullURL != "https://example.com/x/a.txt" {
+		t.Errorf("cached link was modified through a returned copy: %q", second.FullURL)
+	}
+}
+
+func TestRepository_SaveClearsNegativeEntry(t *testing.T) {
+	repo := cache.NewRepository(newBackingRepository(t))
+	ctx := context.Background()
+
+	if _, err := repo.FindByToken(ctx, "release-notes"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := repo.Save(ctx, &entity.ShortURL{Token: "release-notes", FullURL: "https://example.com/x/notes.md", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByToken(ctx, "release-notes"); err != nil {
+		t.Errorf("expected the new alias to resolve, got %v", err)
+	}
+}
+
+func TestRepository_DeleteInvalidates(t *testing.T) {
+	repo := cache.NewRepository(newBackingRepository(t))
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &entity.ShortURL{Token: "abcd", FullURL: "https://example.com/x/a.txt", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	repo.FindByToken(ctx, "abcd")
+
+	if err := repo.Delete(ctx, "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindByToken(ctx, "abcd"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestRepository_NeverCachesLimitedLinks(t *testing.T) {
+	backing := newBackingRepository(t)
+	repo := cache.NewRepository(backing)
+	ctx := context.Background()
+
+	limited := &entity.ShortURL{Token: "abcd", FullURL: "https://example.com/x/a.txt", CreatedAt: time.Now(), MaxDownloads: 2}
+	if err := repo.Save(ctx, limited); err != nil {
+		t.Fatal(err)
+	}
+	repo.FindByToken(ctx, "abcd")
+
+	// A download counted by another replica goes straight to the database.
+	if err := backing.ConsumeDownload(ctx, "abcd"); err != nil {
+		t.Fatal(err)
+	}
+	shortURL, err := repo.FindByToken(ctx, "abcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shortURL.Downloads != 1 {
+		t.Errorf("expected 1 download, got %d", shortURL.Downloads)
+	}
+}
+
+func TestRepository_EntriesExpire(t *testing.T) {
+	backing := newBackingRepository(t)
+	repo := cache.NewRepository(backing, cache.WithTTL(time.Hour), cache.WithNegativeTTL(20*time.Millisecond))
+	ctx := context.Background()
+
+	expiring := &entity.ShortURL{
+		Token:     "abcd",
+		FullURL:   "https://example.com/x/a.txt",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(20 * time.Millisecond),
+	}
+	if err := repo.Save(ctx, expiring); err != nil {
+		t.Fatal(err)
+	}
+	repo.FindByToken(ctx, "abcd")
+	repo.FindByToken(ctx, "efgh")
+
+	time.Sleep(40 * time.Millisecond)
+	repo.FindByToken(ctx, "abcd")
+	repo.FindByToken(ctx, "efgh")
+
+	if got := backing.finds.Load(); got != 4 {
+		t.Errorf("expected expired entries to be looked up again, got %d lookups", got)
+	}
+}
+
+func TestRepository_EvictsLeastRecentlyUsed(t *testing.T) {
+	backing := newBackingRepository(t)
+	repo := cache.NewRepository(backing, cache.WithSize(2))
+	ctx := context.Background()
+
+	repo.FindByToken(ctx, "aaaa")
+	repo.FindByToken(ctx, "bbbb")
+	repo.FindByToken(ctx, "aaaa")
+	repo.FindByToken(ctx, "cccc") // evicts bbbb
+
+	repo.FindByToken(ctx, "aaaa")
+	repo.FindByToken(ctx, "bbbb")
+
+	if got := backing.finds.Load(); got != 4 {
+		t.Errorf("expected 4 database lookups, got %d", got)
+	}
+	if stats := repo.CacheStats(); stats.Evictions != 2 || stats.Entries != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
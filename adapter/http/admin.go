@@ -0,0 +1,93 @@
+package http
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// handleBackup streams a database snapshot to an admin.
+func (h *Handler) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r, h.backupUC != nil) {
+		return
+	}
+
+	filename := fmt.Sprintf("shortener-%s.db", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	body := &writeTracker{ResponseWriter: w}
+	if err := h.backupUC.Execute(r.Context(), body); err != nil {
+		log.Printf("backup error: %v", err)
+		if !body.written {
+			w.Header().Del("Content-Disposition")
+			writeError(w, r, "Backup failed", http.StatusInternalServerError)
+		}
+	}
+}
+
+type cacheStatsDocument struct {
+	Hits         int64 `json:"hits"`
+	NegativeHits int64 `json:"negative_hits"`
+	Misses       int64 `json:"misses"`
+	Evictions    int64 `json:"evictions"`
+	Entries      int   `json:"entries"`
+}
+
+// handleCacheStats reports the link cache counters to an admin.
+func (h *Handler) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r, h.cacheStats != nil) {
+		return
+	}
+
+	stats := h.cacheStats.CacheStats()
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, cacheStatsDocument{
+		Hits:         stats.Hits,
+		NegativeHits: stats.NegativeHits,
+		Misses:       stats.Misses,
+		Evictions:    stats.Evictions,
+		Entries:      stats.Entries,
+	})
+}
+
+// authorizeAdmin answers admin endpoints that are disabled, unauthorized or
+// asked for with a method other than GET, and reports whether to go on.
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request, enabled bool) bool {
+	if !enabled {
+		writeError(w, r, "Not found", http.StatusNotFound)
+		return false
+	}
+	if !h.isAdmin(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="transfer-shortener admin"`)
+		writeError(w, r, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) isAdmin(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
+// writeTracker notes whether a response body has started, after which an
+// error can no longer be reported with a status code.
+type writeTracker struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (t *writeTracker) Write(p []byte) (int, error) {
+	t.written = true
+	return t.ResponseWriter.Write(p)
+}
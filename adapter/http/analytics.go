@@ -0,0 +1,101 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"transfer-shortener/domain/entity"
+)
+
+type statsDocument struct {
+	Token          string           `json:"token"`
+	Clicks         int64            `json:"clicks"`
+	LastAccessedAt *time.Time       `json:"last_accessed_at,omitempty"`
+	Referrers      map[string]int64 `json:"referrers"`
+	Clients        map[string]int64 `json:"clients"`
+	Days           map[string]int64 `json:"days"`
+}
+
+// recordHit counts a download of shortURL. HEAD requests only look, so they
+// are not counted.
+func (h *Handler) recordHit(r *http.Request, shortURL *entity.ShortURL) {
+	if h.hits == nil || r.Method == http.MethodHead {
+		return
+	}
+	h.hits.Record(entity.Hit{
+		Token:    shortURL.Token,
+		At:       time.Now(),
+		Referrer: referrerHost(r.Referer()),
+		Client:   clientClass(r.UserAgent()),
+	})
+}
+
+func (h *Handler) handleStatsDocument(w http.ResponseWriter, r *http.Request, token string) {
+	if h.statsUC == nil {
+		writeError(w, r, "Not found", http.StatusNotFound)
+		return
+	}
+
+	shortURL, ok := h.resolveDocument(w, r, token)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsUC.Execute(r.Context(), shortURL.Token)
+	if err != nil {
+		log.Printf("stats error for %s: %v", shortURL.Token, err)
+		writeError(w, r, "Failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+
+	doc := statsDocument{
+		Token:     stats.Token,
+		Clicks:    stats.Hits,
+		Referrers: stats.Referrers,
+		Clients:   stats.Clients,
+		Days:      stats.Days,
+	}
+	if !stats.LastAccess.IsZero() {
+		lastAccess := stats.LastAccess.UTC()
+		doc.LastAccessedAt = &lastAccess
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// referrerHost keeps only the host of a Referer header so stored buckets
+// carry no paths or query strings.
+func referrerHost(referer string) string {
+	parsed, err := url.Parse(referer)
+	if err != nil || parsed.Hostname() == "" {
+		return "direct"
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+var (
+	botMarkers = []string{"bot", "crawler", "spider", "slurp", "preview", "facebookexternalhit", "whatsapp"}
+	cliMarkers = []string{"curl/", "wget/", "httpie/", "python-requests/", "python-urllib/", "go-http-client/", "aria2/", "powershell/", "libwww-perl/"}
+)
+
+// clientClass sorts a User-Agent into browser, cli, bot or other. Link
+// unfurlers mimic browsers, so bot markers are checked first.
+func clientClass(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, marker := range botMarkers {
+		if strings.Contains(ua, marker) {
+			return entity.ClientBot
+		}
+	}
+	for _, marker := range cliMarkers {
+		if strings.Contains(ua, marker) {
+			return entity.ClientCLI
+		}
+	}
+	if strings.HasPrefix(ua, "mozilla/") {
+		return entity.ClientBrowser
+	}
+	return entity.ClientOther
+}
@@ -2,32 +2,209 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"rsc.io/qr"
+
 	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/usecase"
 )
 
 type CreateShortURLUseCase interface {
-	Execute(ctx context.Context, fullURL string) (*entity.ShortURL, error)
+	ExecuteBatch(ctx context.Context, inputs []usecase.CreateShortURLInput) ([]*entity.ShortURL, error)
+	CheckAlias(ctx context.Context, alias string) error
 }
 
 type ResolveShortURLUseCase interface {
-	Execute(ctx context.Context, token string) (string, error)
+	Execute(ctx context.Context, token string) (*entity.ShortURL, error)
+}
+
+type DeleteShortURLUseCase interface {
+	Execute(ctx context.Context, token, deleteToken string) error
+}
+
+// HitRecorder takes link hits for asynchronous storage; Record must not block.
+type HitRecorder interface {
+	Record(hit entity.Hit)
+}
+
+type LinkStatsUseCase interface {
+	Execute(ctx context.Context, token string) (*entity.LinkStats, error)
+}
+
+// ConsumeDownloadUseCase counts a download against a link's Max-Downloads,
+// failing with usecase.ErrExpired once none are left. Release gives back a
+// download the backend failed to deliver.
+type ConsumeDownloadUseCase interface {
+	Execute(ctx context.Context, shortURL *entity.ShortURL) error
+	Release(ctx context.Context, shortURL *entity.ShortURL) error
+}
+
+// VerifyPasswordUseCase checks the password of a protected link, failing with
+// usecase.ErrPasswordRequired, ErrWrongPassword or ErrTooManyAttempts.
+type VerifyPasswordUseCase interface {
+	Execute(ctx context.Context, shortURL *entity.ShortURL, password string) error
+}
+
+// BurnShortURLUseCase deletes the backend file of a one-time link after its
+// download.
+type BurnShortURLUseCase interface {
+	Execute(ctx context.Context, shortURL *entity.ShortURL) error
+}
+
+// BackupUseCase writes a consistent snapshot of the link database.
+type BackupUseCase interface {
+	Execute(ctx context.Context, w io.Writer) error
+}
+
+// CacheStats counts link cache lookups since startup.
+type CacheStats struct {
+	Hits         int64
+	NegativeHits int64
+	Misses       int64
+	Evictions    int64
+	Entries      int
+}
+
+// CacheStatsSource reports the link cache counters.
+type CacheStatsSource interface {
+	CacheStats() CacheStats
 }
 
 type BackendProxy interface {
-	ProxyUpload(w http.ResponseWriter, r *http.Request) (string, error)
+	ProxyUpload(w http.ResponseWriter, r *http.Request) (UploadResult, error)
 	ProxyGet(w http.ResponseWriter, r *http.Request)
+	ProxyDelete(w http.ResponseWriter, r *http.Request)
+	Stat(ctx context.Context, fullURL string) (FileInfo, error)
+	DeleteFile(ctx context.Context, deleteURL string) error
+}
+
+// ResolveMode decides how a resolved short link reaches the client.
+type ResolveMode string
+
+const (
+	// ResolveModeRedirect answers with a 307 to the transfer.sh URL.
+	ResolveModeRedirect ResolveMode = "redirect"
+	// ResolveModeProxy streams the file from the backend under the short URL.
+	ResolveModeProxy ResolveMode = "proxy"
+)
+
+func ParseResolveMode(value string) (ResolveMode, error) {
+	switch mode := ResolveMode(value); mode {
+	case ResolveModeRedirect, ResolveModeProxy:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown resolve mode %q", value)
+	}
 }
 
 type Handler struct {
-	createUC  CreateShortURLUseCase
-	resolveUC ResolveShortURLUseCase
-	proxy     BackendProxy
-	publicURL string
+	createUC    CreateShortURLUseCase
+	resolveUC   ResolveShortURLUseCase
+	deleteUC    DeleteShortURLUseCase
+	hits        HitRecorder
+	statsUC     LinkStatsUseCase
+	downloadsUC ConsumeDownloadUseCase
+	passwordUC  VerifyPasswordUseCase
+	burnUC      BurnShortURLUseCase
+	backupUC    BackupUseCase
+	cacheStats  CacheStatsSource
+	adminToken  string
+	proxy       BackendProxy
+	publicURL   string
+	resolveMode ResolveMode
+}
+
+// Option configures optional Handler features.
+type Option func(*Handler)
+
+// WithResolveMode sets the default resolve mode; redirect when not given.
+func WithResolveMode(mode ResolveMode) Option {
+	return func(h *Handler) {
+		h.resolveMode = mode
+	}
+}
+
+// WithDeleteShortURL enables DELETE /{short}, removing both the backend file
+// and the link. Without it short delete links are only forwarded to the backend.
+func WithDeleteShortURL(deleteUC DeleteShortURLUseCase) Option {
+	return func(h *Handler) {
+		h.deleteUC = deleteUC
+	}
+}
+
+// WithHitRecorder counts every download of a short link.
+func WithHitRecorder(hits HitRecorder) Option {
+	return func(h *Handler) {
+		h.hits = hits
+	}
+}
+
+// WithLinkStats adds click counts to link metadata and enables
+// /api/v1/links/{short}/stats.
+func WithLinkStats(statsUC LinkStatsUseCase) Option {
+	return func(h *Handler) {
+		h.statsUC = statsUC
+	}
+}
+
+// WithDownloadLimits makes the shortener enforce Max-Downloads instead of
+// transfer.sh: the header is recorded and not forwarded, limited links are
+// always streamed, and link-preview bots get the landing page instead of the
+// file so they do not use up downloads.
+func WithDownloadLimits(downloadsUC ConsumeDownloadUseCase) Option {
+	return func(h *Handler) {
+		h.downloadsUC = downloadsUC
+	}
+}
+
+// WithPasswords lets uploaders protect links with X-Short-Password. Without
+// it such uploads are refused rather than stored unprotected.
+func WithPasswords(passwordUC VerifyPasswordUseCase) Option {
+	return func(h *Handler) {
+		h.passwordUC = passwordUC
+	}
+}
+
+// WithBurnAfterReading lets uploaders create one-time links with
+// X-Short-Once: 1. It needs WithDownloadLimits, which counts the download,
+// and also deletes the file of a limited link after its last download.
+func WithBurnAfterReading(burnUC BurnShortURLUseCase) Option {
+	return func(h *Handler) {
+		h.burnUC = burnUC
+	}
+}
+
+// WithBackup serves database snapshots at /api/v1/admin/backup to requests
+// bearing adminToken. An empty token leaves the endpoint disabled.
+func WithBackup(backupUC BackupUseCase, adminToken string) Option {
+	return func(h *Handler) {
+		if adminToken == "" {
+			return
+		}
+		h.backupUC = backupUC
+		h.adminToken = adminToken
+	}
+}
+
+// WithCacheStats serves the link cache counters at /api/v1/admin/cache to
+// requests bearing adminToken. An empty token leaves the endpoint disabled.
+func WithCacheStats(cacheStats CacheStatsSource, adminToken string) Option {
+	return func(h *Handler) {
+		if adminToken == "" {
+			return
+		}
+		h.cacheStats = cacheStats
+		h.adminToken = adminToken
+	}
 }
 
 func NewHandler(
@@ -35,52 +212,232 @@ func NewHandler(
 	resolveUC ResolveShortURLUseCase,
 	proxy BackendProxy,
 	publicURL string,
+	opts ...Option,
 ) *Handler {
-	return &Handler{
-		createUC:  createUC,
-		resolveUC: resolveUC,
-		proxy:     proxy,
-		publicURL: publicURL,
+	h := &Handler{
+		createUC:    createUC,
+		resolveUC:   resolveUC,
+		proxy:       proxy,
+		publicURL:   publicURL,
+		resolveMode: ResolveModeRedirect,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case r.URL.Path == "/health":
 		h.handleHealth(w, r)
+	case strings.HasPrefix(r.URL.Path, apiPrefix):
+		h.handleAPI(w, r)
+	case r.Method == http.MethodPost && isPasswordForm(r):
+		h.handlePasswordForm(w, r)
 	case r.Method == http.MethodPut || r.Method == http.MethodPost:
 		h.handleUpload(w, r)
-	case r.Method == http.MethodGet && r.URL.Path == "/":
+	case isRead(r) && r.URL.Path == "/":
 		h.handleIndex(w, r)
-	case r.Method == http.MethodGet:
+	case isRead(r):
 		h.handleGet(w, r)
+	case r.Method == http.MethodDelete:
+		h.handleDelete(w, r)
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
 func (h *Handler) handleUpload(w http.ResponseWriter, r *http.Request) {
-	fullURL, err := h.proxy.ProxyUpload(w, r)
+	// Reject a bad or taken alias before the file is sent to the backend.
+	alias := requestedAlias(r)
+	if alias != "" {
+		if err := h.createUC.CheckAlias(r.Context(), alias); err != nil {
+			writeAliasError(w, r, err)
+			return
+		}
+	}
+
+	password := r.Header.Get("X-Short-Password")
+	if password != "" && h.passwordUC == nil {
+		writeError(w, r, "Password protection is not enabled", http.StatusBadRequest)
+		return
+	}
+
+	once := isTruthy(r.Header.Get("X-Short-Once"))
+	if once && (h.burnUC == nil || h.downloadsUC == nil) {
+		writeError(w, r, "One-time links are not enabled", http.StatusBadRequest)
+		return
+	}
+
+	// Max-Downloads is enforced here, so transfer.sh must not count too.
+	downloads := 0
+	backendReq := r
+	if h.downloadsUC != nil {
+		downloads = maxDownloads(r)
+		backendReq = r.Clone(r.Context())
+		backendReq.Header.Del("Max-Downloads")
+	}
+
+	upload, err := h.proxy.ProxyUpload(w, backendReq)
 	if err != nil {
 		log.Printf("proxy error: %v", err)
-		http.Error(w, "Backend error", http.StatusBadGateway)
+		writeError(w, r, "Backend error", http.StatusBadGateway)
 		return
 	}
 
-	shortURL, err := h.createUC.Execute(r.Context(), fullURL)
+	// Each file could be read once through its own link and again through
+	// the bundle, so a one-time upload takes a single file.
+	if once && len(upload.Files) > 1 {
+		h.discardUpload(r.Context(), upload)
+		writeError(w, r, "One-time links take a single file", http.StatusBadRequest)
+		return
+	}
+
+	// With several files the alias names the bundle link instead
+	days := maxDays(r)
+	bundled := len(upload.Files) > 1
+	inputs := make([]usecase.CreateShortURLInput, 0, len(upload.Files)+1)
+	for _, file := range upload.Files {
+		input := usecase.CreateShortURLInput{
+			FullURL:      file.FullURL,
+			MaxDays:      days,
+			DeleteToken:  deleteToken(file.FullURL, file.DeleteURL),
+			MaxDownloads: downloads,
+			Password:     password,
+			Once:         once,
+		}
+		if !bundled {
+			input.Alias = alias
+		}
+		inputs = append(inputs, input)
+	}
+	if bundled {
+		bundle, err := bundleURL(upload.Files)
+		if err != nil {
+			log.Printf("bundle error: %v", err)
+			writeError(w, r, "Backend error", http.StatusBadGateway)
+			return
+		}
+		inputs = append(inputs, usecase.CreateShortURLInput{
+			FullURL:      bundle,
+			MaxDays:      days,
+			Alias:        alias,
+			MaxDownloads: downloads,
+			Password:     password,
+			Once:         once,
+		})
+	}
+
+	shortURLs, err := h.createUC.ExecuteBatch(r.Context(), inputs)
+	if errors.Is(err, usecase.ErrAliasTaken) {
+		// Taken since CheckAlias; nothing will link to the uploaded files.
+		h.discardUpload(r.Context(), upload)
+		writeAliasError(w, r, err)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to create short URL", http.StatusInternalServerError)
+		writeError(w, r, "Failed to create short URL", http.StatusInternalServerError)
+		return
+	}
+
+	var bundle *entity.ShortURL
+	if bundled {
+		bundle = shortURLs[len(shortURLs)-1]
+		shortURLs = shortURLs[:len(shortURLs)-1]
+		w.Header().Set("X-Short-Url-Bundle", fmt.Sprintf("%s/%s", h.publicURL, bundle.Token))
+	}
+
+	// Keep the transfer.sh header so existing clients still find the delete
+	// link, and offer a shortened equivalent next to it.
+	for i, shortURL := range shortURLs {
+		if deleteURL := upload.Files[i].DeleteURL; deleteURL != "" {
+			w.Header().Add("X-Url-Delete", deleteURL)
+		}
+		if shortURL.DeleteToken != "" {
+			w.Header().Add("X-Short-Url-Delete", h.shortDeleteURL(shortURL))
+		}
+	}
+
+	if wantsJSON(r) {
+		doc := uploadDocument{Files: make([]linkDocument, 0, len(shortURLs))}
+		for i, shortURL := range shortURLs {
+			file := h.linkDocument(r.Context(), shortURL)
+			file.FullURL = shortURL.FullURL
+			file.DeleteURL = upload.Files[i].DeleteURL
+			if shortURL.DeleteToken != "" {
+				file.ShortDeleteURL = h.shortDeleteURL(shortURL)
+			}
+			doc.Files = append(doc.Files, file)
+		}
+		if bundle != nil {
+			bundleDoc := h.linkDocument(r.Context(), bundle)
+			bundleDoc.FullURL = bundle.FullURL
+			doc.Bundle = &bundleDoc
+		}
+		writeJSON(w, http.StatusOK, doc)
+		return
+	}
+
+	if acceptsHTML(r) {
+		h.serveUploadPage(w, shortURLs, bundle)
 		return
 	}
 
-	result := fmt.Sprintf("%s/%s\n", h.publicURL, shortURL.Token)
+	// ?qr=1 prints a terminal QR code below each link
+	withQR := r.URL.Query().Get("qr") == "1"
+	var result strings.Builder
+	for _, shortURL := range shortURLs {
+		link := fmt.Sprintf("%s/%s", h.publicURL, shortURL.Token)
+		fmt.Fprintf(&result, "%s\n", link)
+		if withQR {
+			if code, err := qr.Encode(link, qr.M); err == nil {
+				result.WriteString(qrANSI(code))
+			}
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(result))
+	w.Write([]byte(result.String()))
+}
+
+func (h *Handler) shortDeleteURL(shortURL *entity.ShortURL) string {
+	return fmt.Sprintf("%s/%s/%s", h.publicURL, shortURL.Token, shortURL.DeleteToken)
 }
 
 func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/")
 
+	// The serve helpers report false for a token they do not know, so the
+	// path can still be tried against the backend
+	if short, ok := infoToken(path); ok {
+		if h.serveInfo(w, r, short) {
+			return
+		}
+	}
+
+	if short, format, ok := qrToken(path); ok {
+		if h.serveQR(w, r, short, qrFormat(r, format)) {
+			return
+		}
+	}
+
+	// "/{short}/inline", "/{short}/get" and "/{short}/raw" select a transfer.sh
+	// variant of the linked file
+	if short, variant, ok := strings.Cut(path, "/"); ok && isLinkVariant(variant) {
+		if h.serveVariant(w, r, short, variant) {
+			return
+		}
+	}
+
+	// "/{bundle}.zip", "/{bundle}.tar" and "/{bundle}.tar.gz" pick the archive
+	// format of a multi-file bundle link
+	if short, format, ok := strings.Cut(path, "."); ok && isArchiveFormat(format) && !strings.Contains(path, "/") {
+		if h.serveArchive(w, r, short, format) {
+			return
+		}
+	}
+
 	// If path contains slash (e.g., "abc12/file.txt"), proxy to backend
 	if strings.Contains(path, "/") {
 		h.proxy.ProxyGet(w, r)
@@ -88,14 +445,257 @@ func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to resolve as short token
-	fullURL, err := h.resolveUC.Execute(r.Context(), path)
+	shortURL, err := h.resolveUC.Execute(r.Context(), path)
+	if errors.Is(err, usecase.ErrExpired) {
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return
+	}
 	if err != nil {
 		// Not a short token, proxy to backend
 		h.proxy.ProxyGet(w, r)
 		return
 	}
 
-	http.Redirect(w, r, fullURL, http.StatusTemporaryRedirect)
+	// Response differs for browsers, scripts and curl
+	w.Header().Add("Vary", "Accept")
+
+	r, ok := h.unlock(w, r, shortURL)
+	if !ok {
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, h.linkDocument(r.Context(), shortURL))
+		return
+	}
+	// The password form stands in for the landing page of protected links
+	if wantsLandingPage(r) && !shortURL.HasPassword() {
+		h.serveLandingPage(w, r, shortURL)
+		return
+	}
+
+	h.serveLink(w, r, shortURL, shortURL.FullURL, h.wantsRedirect(r))
+}
+
+// serveLink sends the client to target on behalf of shortURL. Links with a
+// download limit or password are always streamed so the backend URL never
+// leaks, and only downloads by people count against the limit. Each counted
+// download is the whole file, and the one that uses up the limit, like the
+// download of a one-time link, deletes the backend file once streamed.
+func (h *Handler) serveLink(w http.ResponseWriter, r *http.Request, shortURL *entity.ShortURL, target string, redirect bool) {
+	if shortURL.HasPassword() {
+		redirect = false
+	}
+	limited := h.downloadsUC != nil && shortURL.MaxDownloads > 0
+	consumed, burn := false, false
+	if limited {
+		if clientClass(r.UserAgent()) == entity.ClientBot {
+			h.serveLandingPage(w, r, shortURL)
+			return
+		}
+		redirect = false
+		// Whatever the method, anything but HEAD gets the file from the backend
+		if r.Method != http.MethodHead {
+			err := h.downloadsUC.Execute(r.Context(), shortURL)
+			if errors.Is(err, usecase.ErrExpired) {
+				writeError(w, r, "Short URL has expired", http.StatusGone)
+				return
+			}
+			if err != nil {
+				log.Printf("download limit error for %s: %v", shortURL.Token, err)
+				writeError(w, r, "Internal error", http.StatusInternalServerError)
+				return
+			}
+			consumed = true
+			burn = h.burnUC != nil && (shortURL.BurnAfterReading || shortURL.DownloadsExhausted())
+		}
+	}
+
+	setLinkHeaders(w, shortURL)
+	h.recordHit(r, shortURL)
+	if limited {
+		// A resumed or partial download would count as a whole one
+		r = r.Clone(r.Context())
+		r.Header.Del("Range")
+		r.Header.Del("If-Range")
+	}
+	if !consumed {
+		h.serveResolved(w, r, target, redirect)
+		return
+	}
+
+	status := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.serveResolved(status, r, target, false)
+	// The client may already be gone; the counter and file must be settled
+	// regardless
+	ctx := context.WithoutCancel(r.Context())
+	if status.status >= http.StatusMultipleChoices {
+		log.Printf("download of %s not counted: backend answered %d", shortURL.Token, status.status)
+		if err := h.downloadsUC.Release(ctx, shortURL); err != nil {
+			log.Printf("download release error for %s: %v", shortURL.Token, err)
+		}
+		return
+	}
+	if !burn {
+		return
+	}
+	if err := h.burnUC.Execute(ctx, shortURL); err != nil {
+		log.Printf("burn error for %s: %v", shortURL.Token, err)
+	}
+}
+
+// serveResolved sends the client to target, either as a redirect or by
+// streaming the backend response. Proxying forwards the request headers, so
+// Range and conditional requests behave as if the client hit the backend.
+func (h *Handler) serveResolved(w http.ResponseWriter, r *http.Request, target string, redirect bool) {
+	if redirect {
+		http.Redirect(w, r, target, http.StatusTemporaryRedirect)
+		return
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	r = r.Clone(r.Context())
+	r.URL.Path = parsed.Path
+	r.URL.RawPath = ""
+	r.URL.RawQuery = ""
+	h.proxy.ProxyGet(w, r)
+}
+
+// wantsRedirect applies the deployment's resolve mode unless the request
+// overrides it with ?redirect=1 or ?redirect=0. HEAD is always answered from
+// the backend so clients see the file's metadata under the short URL.
+func (h *Handler) wantsRedirect(r *http.Request) bool {
+	if r.Method == http.MethodHead {
+		return false
+	}
+	if value := r.URL.Query().Get("redirect"); value != "" {
+		redirect, err := strconv.ParseBool(value)
+		if err == nil {
+			return redirect
+		}
+	}
+	return h.resolveMode == ResolveModeRedirect
+}
+
+// serveVariant serves the inline or download form of the linked file, always
+// streaming the inline form for "raw".
+func (h *Handler) serveVariant(w http.ResponseWriter, r *http.Request, short, variant string) bool {
+	shortURL, err := h.resolveUC.Execute(r.Context(), short)
+	if errors.Is(err, usecase.ErrExpired) {
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	r, ok := h.unlock(w, r, shortURL)
+	if !ok {
+		return true
+	}
+
+	if variant == variantRaw {
+		inlineURL, err := variantURL(shortURL.FullURL, variantInline)
+		if err != nil {
+			return false
+		}
+		h.serveLink(w, r, shortURL, inlineURL, false)
+		return true
+	}
+
+	target, err := variantURL(shortURL.FullURL, variant)
+	if err != nil {
+		return false
+	}
+	h.serveLink(w, r, shortURL, target, h.wantsRedirect(r))
+	return true
+}
+
+// serveArchive serves a bundle link in the requested archive format; any
+// other link is left to the backend (e.g. a plain file such as "/notes.tar").
+func (h *Handler) serveArchive(w http.ResponseWriter, r *http.Request, short, format string) bool {
+	shortURL, err := h.resolveUC.Execute(r.Context(), short)
+	if errors.Is(err, usecase.ErrExpired) {
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	r, ok := h.unlock(w, r, shortURL)
+	if !ok {
+		return true
+	}
+
+	target, ok := archiveURL(shortURL.FullURL, format)
+	if !ok {
+		return false
+	}
+	h.serveLink(w, r, shortURL, target, h.wantsRedirect(r))
+	return true
+}
+
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	// "/{short}" with X-Delete-Token or "/{short}/{deletion token}"; the
+	// three-segment transfer.sh delete URL still goes to the backend as is.
+	if h.deleteUC != nil && strings.Count(path, "/") <= 1 {
+		short, secret, _ := strings.Cut(path, "/")
+		if secret == "" {
+			secret = r.Header.Get("X-Delete-Token")
+		}
+		h.deleteShortURL(w, r, short, secret)
+		return
+	}
+
+	// Shortened delete link "/{short}/{deletion token}": expand the short token
+	// to the transfer.sh file path and let the backend check the secret.
+	short, secret, ok := strings.Cut(path, "/")
+	if ok && secret != "" && !strings.Contains(secret, "/") {
+		if shortURL, err := h.resolveUC.Execute(r.Context(), short); err == nil {
+			if parsed, err := url.Parse(shortURL.FullURL); err == nil {
+				r = r.Clone(r.Context())
+				r.URL.Path = parsed.Path + "/" + secret
+			}
+		}
+	}
+
+	h.proxy.ProxyDelete(w, r)
+}
+
+func (h *Handler) deleteShortURL(w http.ResponseWriter, r *http.Request, token, deleteToken string) {
+	err := h.deleteUC.Execute(r.Context(), token, deleteToken)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, usecase.ErrEmptyToken):
+		http.Error(w, "Short URL not found", http.StatusNotFound)
+	case errors.Is(err, usecase.ErrInvalidDeleteToken):
+		http.Error(w, "Invalid deletion token", http.StatusForbidden)
+	case errors.Is(err, usecase.ErrFileDeletion):
+		log.Printf("delete error: %v", err)
+		http.Error(w, "Backend error", http.StatusBadGateway)
+	default:
+		log.Printf("delete error: %v", err)
+		http.Error(w, "Failed to delete short URL", http.StatusInternalServerError)
+	}
+}
+
+// discardUpload deletes files the backend stored for a rejected upload.
+func (h *Handler) discardUpload(ctx context.Context, upload UploadResult) {
+	for _, file := range upload.Files {
+		if file.DeleteURL == "" {
+			log.Printf("discard skipped for %s: no delete URL", file.FullURL)
+			continue
+		}
+		if err := h.proxy.DeleteFile(context.WithoutCancel(ctx), file.DeleteURL); err != nil {
+			log.Printf("discard error for %s: %v", file.FullURL, err)
+		}
+	}
 }
 
 func (h *Handler) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -120,3 +720,161 @@ func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
 }
+
+func isRead(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// setLinkHeaders adds the short link's own metadata to the response.
+func setLinkHeaders(w http.ResponseWriter, shortURL *entity.ShortURL) {
+	if !shortURL.ExpiresAt.IsZero() {
+		w.Header().Set("X-Short-Expires", shortURL.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if remaining := shortURL.RemainingDownloads(); remaining >= 0 {
+		w.Header().Set("X-Short-Remaining-Downloads", strconv.Itoa(remaining))
+	}
+}
+
+// Link variants map to transfer.sh's "/inline/" and "/get/" path prefixes;
+// "raw" streams the inline form instead of redirecting.
+const (
+	variantInline = "inline"
+	variantGet    = "get"
+	variantRaw    = "raw"
+)
+
+func isLinkVariant(variant string) bool {
+	return variant == variantInline || variant == variantGet || variant == variantRaw
+}
+
+// variantURL turns "https://host/abc12/file.txt" into
+// "https://host/{prefix}/abc12/file.txt".
+func variantURL(fullURL, prefix string) (string, error) {
+	parsed, err := url.Parse(fullURL)
+	if err != nil {
+		return "", err
+	}
+	parsed.Path = "/" + prefix + parsed.Path
+	parsed.RawPath = ""
+	return parsed.String(), nil
+}
+
+// transfer.sh serves several uploads as one archive under
+// "/{format}/{token}/{file},{token}/{file}". Bundle links store the zip form.
+var archiveFormats = []string{"zip", "tar", "tar.gz"}
+
+func isArchiveFormat(format string) bool {
+	for _, f := range archiveFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleURL builds the zip archive URL covering all uploaded files.
+func bundleURL(files []UploadedFile) (string, error) {
+	var bundle *url.URL
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		parsed, err := url.Parse(file.FullURL)
+		if err != nil {
+			return "", err
+		}
+		if bundle == nil {
+			bundle = parsed
+		}
+		paths = append(paths, strings.TrimPrefix(parsed.EscapedPath(), "/"))
+	}
+
+	archive := *bundle
+	archive.RawPath = "/zip/" + strings.Join(paths, ",")
+	archive.Path, _ = url.PathUnescape(archive.RawPath)
+	return archive.String(), nil
+}
+
+// archiveURL switches a bundle URL to the given archive format. It reports
+// false when fullURL is not an archive URL.
+func archiveURL(fullURL, format string) (string, bool) {
+	parsed, err := url.Parse(fullURL)
+	if err != nil {
+		return "", false
+	}
+	current, files, ok := strings.Cut(strings.TrimPrefix(parsed.EscapedPath(), "/"), "/")
+	if !ok || !isArchiveFormat(current) {
+		return "", false
+	}
+
+	parsed.RawPath = "/" + format + "/" + files
+	parsed.Path, _ = url.PathUnescape(parsed.RawPath)
+	return parsed.String(), true
+}
+
+// requestedAlias returns the vanity alias asked for via the X-Short-Alias
+// header or the alias query parameter.
+func requestedAlias(r *http.Request) string {
+	if alias := r.Header.Get("X-Short-Alias"); alias != "" {
+		return alias
+	}
+	return r.URL.Query().Get("alias")
+}
+
+func writeAliasError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidAlias), errors.Is(err, entity.ErrReservedAlias):
+		writeError(w, r, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, usecase.ErrAliasTaken):
+		writeError(w, r, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("alias check error: %v", err)
+		writeError(w, r, "Failed to create short URL", http.StatusInternalServerError)
+	}
+}
+
+// deleteToken extracts the transfer.sh deletion secret, the path segment that
+// follows the file URL in the delete URL.
+func deleteToken(fullURL, deleteURL string) string {
+	token, ok := strings.CutPrefix(deleteURL, fullURL+"/")
+	if !ok || strings.Contains(token, "/") {
+		return ""
+	}
+	return token
+}
+
+// maxDays reads the transfer.sh Max-Days upload header. Missing or malformed
+// values mean no expiry, matching how the backend treats them.
+func maxDays(r *http.Request) int {
+	days, err := strconv.Atoi(r.Header.Get("Max-Days"))
+	if err != nil || days < 0 {
+		return 0
+	}
+	return days
+}
+
+func maxDownloads(r *http.Request) int {
+	downloads, err := strconv.Atoi(r.Header.Get("Max-Downloads"))
+	if err != nil || downloads < 0 {
+		return 0
+	}
+	return downloads
+}
+
+func isTruthy(value string) bool {
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// statusRecorder remembers the status code written through it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -2,50 +2,85 @@ package http_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
-	"transfer-shortener/domain/entity"
 	handler "transfer-shortener/adapter/http"
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/usecase"
 )
 
 type mockCreateShortURL struct {
-	executeFunc func(ctx context.Context, fullURL string) (*entity.ShortURL, error)
+	executeFunc    func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error)
+	checkAliasFunc func(ctx context.Context, alias string) error
 }
 
-func (m *mockCreateShortURL) Execute(ctx context.Context, fullURL string) (*entity.ShortURL, error) {
-	if m.executeFunc != nil {
-		return m.executeFunc(ctx, fullURL)
+// ExecuteBatch runs executeFunc once per input so single-file tests stay simple.
+func (m *mockCreateShortURL) ExecuteBatch(ctx context.Context, inputs []usecase.CreateShortURLInput) ([]*entity.ShortURL, error) {
+	if m.executeFunc == nil {
+		return nil, errors.New("not implemented")
 	}
-	return nil, errors.New("not implemented")
+	var shortURLs []*entity.ShortURL
+	for _, input := range inputs {
+		shortURL, err := m.executeFunc(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
+	return shortURLs, nil
+}
+
+func (m *mockCreateShortURL) CheckAlias(ctx context.Context, alias string) error {
+	if m.checkAliasFunc != nil {
+		return m.checkAliasFunc(ctx, alias)
+	}
+	return nil
 }
 
 type mockResolveShortURL struct {
-	executeFunc func(ctx context.Context, token string) (string, error)
+	executeFunc func(ctx context.Context, token string) (*entity.ShortURL, error)
 }
 
-func (m *mockResolveShortURL) Execute(ctx context.Context, token string) (string, error) {
+func (m *mockResolveShortURL) Execute(ctx context.Context, token string) (*entity.ShortURL, error) {
 	if m.executeFunc != nil {
 		return m.executeFunc(ctx, token)
 	}
-	return "", errors.New("not implemented")
+	return nil, errors.New("not implemented")
+}
+
+type mockDeleteShortURL struct {
+	executeFunc func(ctx context.Context, token, deleteToken string) error
+}
+
+func (m *mockDeleteShortURL) Execute(ctx context.Context, token, deleteToken string) error {
+	if m.executeFunc != nil {
+		return m.executeFunc(ctx, token, deleteToken)
+	}
+	return errors.New("not implemented")
 }
 
 type mockBackendProxy struct {
-	proxyUploadFunc func(w http.ResponseWriter, r *http.Request) (string, error)
+	proxyUploadFunc func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error)
 	proxyGetFunc    func(w http.ResponseWriter, r *http.Request)
+	proxyDeleteFunc func(w http.ResponseWriter, r *http.Request)
+	statFunc        func(ctx context.Context, fullURL string) (handler.FileInfo, error)
+	deleteFileFunc  func(ctx context.Context, deleteURL string) error
 }
 
-func (m *mockBackendProxy) ProxyUpload(w http.ResponseWriter, r *http.Request) (string, error) {
+func (m *mockBackendProxy) ProxyUpload(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
 	if m.proxyUploadFunc != nil {
 		return m.proxyUploadFunc(w, r)
 	}
-	return "", errors.New("not implemented")
+	return handler.UploadResult{}, errors.New("not implemented")
 }
 
 func (m *mockBackendProxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
@@ -54,131 +89,2413 @@ func (m *mockBackendProxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (m *mockBackendProxy) ProxyDelete(w http.ResponseWriter, r *http.Request) {
+	if m.proxyDeleteFunc != nil {
+		m.proxyDeleteFunc(w, r)
+	}
+}
+
+func (m *mockBackendProxy) Stat(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+	if m.statFunc != nil {
+		return m.statFunc(ctx, fullURL)
+	}
+	return handler.FileInfo{}, errors.New("not implemented")
+}
+
+func (m *mockBackendProxy) DeleteFile(ctx context.Context, deleteURL string) error {
+	if m.deleteFileFunc != nil {
+		return m.deleteFileFunc(ctx, deleteURL)
+	}
+	return errors.New("not implemented")
+}
+
 func TestHandler_Upload_PUT_Success(t *testing.T) {
 	backendURL := "https://transfer.sixtyfive.me/abc12/file.txt"
 
 	createUC := &mockCreateShortURL{
-		executeFunc: func(ctx context.Context, fullURL string) (*entity.ShortURL, error) {
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			return &entity.ShortURL{
+				Token:     "xyz1",
+				FullURL:   input.FullURL,
+				CreatedAt: time.Now(),
+			}, nil
+		},
+	}
+	resolveUC := &mockResolveShortURL{}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: backendURL}}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	expected := "https://transfer.sixtyfive.me/xyz1\n"
+	if string(body) != expected {
+		t.Errorf("expected body %q, got %q", expected, string(body))
+	}
+}
+
+func TestHandler_Upload_POST_Success(t *testing.T) {
+	backendURL := "https://transfer.sixtyfive.me/abc12/file.txt"
+
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
 			return &entity.ShortURL{
 				Token:     "xyz1",
-				FullURL:   fullURL,
+				FullURL:   input.FullURL,
 				CreatedAt: time.Now(),
 			}, nil
 		},
 	}
 	resolveUC := &mockResolveShortURL{}
 	proxy := &mockBackendProxy{
-		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (string, error) {
-			return backendURL, nil
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: backendURL}}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("file content"))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Upload_MultipleFiles_OneLinkPerFile(t *testing.T) {
+	var received []usecase.CreateShortURLInput
+	tokens := []string{"aaa1", "bbb2", "ccc3"}
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			received = append(received, input)
+			return &entity.ShortURL{
+				Token:       tokens[len(received)-1],
+				FullURL:     input.FullURL,
+				DeleteToken: input.DeleteToken,
+				CreatedAt:   time.Now(),
+			}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{
+				{FullURL: "https://transfer.sixtyfive.me/abc12/a.log"},
+				{FullURL: "https://transfer.sixtyfive.me/def34/b.log", DeleteURL: "https://transfer.sixtyfive.me/def34/b.log/s3cr3t"},
+			}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("multipart"))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	expected := "https://transfer.sixtyfive.me/aaa1\nhttps://transfer.sixtyfive.me/bbb2\n"
+	if string(body) != expected {
+		t.Errorf("expected body %q, got %q", expected, string(body))
+	}
+	if received[1].DeleteToken != "s3cr3t" || received[0].DeleteToken != "" {
+		t.Errorf("expected delete token only for the second file, got %+v", received)
+	}
+	if got := rec.Header().Values("X-Short-Url-Delete"); len(got) != 1 || got[0] != "https://transfer.sixtyfive.me/bbb2/s3cr3t" {
+		t.Errorf("expected one short delete URL for the second file, got %v", got)
+	}
+}
+
+func TestHandler_Upload_MultipleFiles_MintsBundleLink(t *testing.T) {
+	var received []usecase.CreateShortURLInput
+	tokens := []string{"aaa1", "bbb2", "release-logs"}
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			received = append(received, input)
+			return &entity.ShortURL{Token: tokens[len(received)-1], FullURL: input.FullURL, CreatedAt: time.Now()}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{
+				{FullURL: "https://transfer.sixtyfive.me/abc12/a.log"},
+				{FullURL: "https://transfer.sixtyfive.me/def34/b%20c.log"},
+			}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("multipart"))
+	req.Header.Set("X-Short-Alias", "release-logs")
+	req.Header.Set("Max-Days", "2")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if len(received) != 3 {
+		t.Fatalf("expected 2 file links and a bundle link, got %d", len(received))
+	}
+	bundle := received[2]
+	if bundle.FullURL != "https://transfer.sixtyfive.me/zip/abc12/a.log,def34/b%20c.log" {
+		t.Errorf("expected zip archive URL, got %s", bundle.FullURL)
+	}
+	if bundle.Alias != "release-logs" || received[0].Alias != "" || received[1].Alias != "" {
+		t.Errorf("expected the alias to name only the bundle, got %+v", received)
+	}
+	if bundle.MaxDays != 2 {
+		t.Errorf("expected bundle to share MaxDays, got %d", bundle.MaxDays)
+	}
+	if got := rec.Header().Get("X-Short-Url-Bundle"); got != "https://transfer.sixtyfive.me/release-logs" {
+		t.Errorf("expected X-Short-Url-Bundle, got %q", got)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	expected := "https://transfer.sixtyfive.me/aaa1\nhttps://transfer.sixtyfive.me/bbb2\n"
+	if string(body) != expected {
+		t.Errorf("expected only file links in body %q, got %q", expected, string(body))
+	}
+}
+
+func TestHandler_Upload_PassesMaxDays(t *testing.T) {
+	var received usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			received = input
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL, CreatedAt: time.Now()}, nil
+		},
+	}
+	resolveUC := &mockResolveShortURL{}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	req.Header.Set("Max-Days", "5")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if received.MaxDays != 5 {
+		t.Errorf("expected MaxDays 5, got %d", received.MaxDays)
+	}
+}
+
+func TestHandler_Upload_ReturnsDeleteURLs(t *testing.T) {
+	var received usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			received = input
+			return &entity.ShortURL{
+				Token:       "xyz1",
+				FullURL:     input.FullURL,
+				DeleteToken: input.DeleteToken,
+				CreatedAt:   time.Now(),
+			}, nil
+		},
+	}
+	resolveUC := &mockResolveShortURL{}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{
+				FullURL:   "https://transfer.sixtyfive.me/abc12/file.txt",
+				DeleteURL: "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t",
+			}}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if received.DeleteToken != "s3cr3t" {
+		t.Errorf("expected DeleteToken s3cr3t, got %q", received.DeleteToken)
+	}
+	if got := rec.Header().Get("X-Url-Delete"); got != "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t" {
+		t.Errorf("expected X-Url-Delete with public delete URL, got %q", got)
+	}
+	if got := rec.Header().Get("X-Short-Url-Delete"); got != "https://transfer.sixtyfive.me/xyz1/s3cr3t" {
+		t.Errorf("expected X-Short-Url-Delete with short delete URL, got %q", got)
+	}
+}
+
+func TestHandler_Delete_ShortLinkExpandsToBackendPath(t *testing.T) {
+	createUC := &mockCreateShortURL{}
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token == "xyz1" {
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+
+	var deletedPath string
+	proxy := &mockBackendProxy{
+		proxyDeleteFunc: func(w http.ResponseWriter, r *http.Request) {
+			deletedPath = r.URL.Path
+			w.WriteHeader(http.StatusOK)
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"short delete link", "/xyz1/s3cr3t", "/abc12/file.txt/s3cr3t"},
+		{"full delete link", "/abc12/file.txt/s3cr3t", "/abc12/file.txt/s3cr3t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if deletedPath != tt.expected {
+				t.Errorf("expected backend delete path %s, got %s", tt.expected, deletedPath)
+			}
+		})
+	}
+}
+
+func TestHandler_Upload_WithAlias(t *testing.T) {
+	var received usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			received = input
+			return &entity.ShortURL{Token: input.Alias, FullURL: input.FullURL, CreatedAt: time.Now()}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/notes.md"}}}, nil
 		},
 	}
 
-	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+	}{
+		{"header", "/notes.md", "release-notes"},
+		{"query parameter", "/notes.md?alias=release-notes", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader("notes"))
+			if tt.header != "" {
+				req.Header.Set("X-Short-Alias", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if received.Alias != "release-notes" {
+				t.Errorf("expected Alias release-notes, got %q", received.Alias)
+			}
+			body, _ := io.ReadAll(rec.Body)
+			if string(body) != "https://transfer.sixtyfive.me/release-notes\n" {
+				t.Errorf("expected aliased short URL, got %q", string(body))
+			}
+		})
+	}
+}
+
+func TestHandler_Upload_AliasRejectedBeforeUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"invalid alias", entity.ErrInvalidAlias, http.StatusBadRequest},
+		{"reserved alias", entity.ErrReservedAlias, http.StatusBadRequest},
+		{"alias taken", usecase.ErrAliasTaken, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createUC := &mockCreateShortURL{
+				checkAliasFunc: func(ctx context.Context, alias string) error {
+					return tt.err
+				},
+			}
+
+			var uploadCalled bool
+			proxy := &mockBackendProxy{
+				proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+					uploadCalled = true
+					return handler.UploadResult{}, nil
+				},
+			}
+
+			h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+			req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("content"))
+			req.Header.Set("X-Short-Alias", "some-alias")
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if uploadCalled {
+				t.Error("file should NOT be uploaded when the alias is rejected")
+			}
+		})
+	}
+}
+
+func TestHandler_Upload_AliasTakenDuringUpload_ReturnsConflict(t *testing.T) {
+	var deleted []string
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			return nil, usecase.ErrAliasTaken
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{
+				FullURL:   "https://transfer.sixtyfive.me/abc12/file.txt",
+				DeleteURL: "https://transfer.sixtyfive.me/abc12/file.txt/del1",
+			}}}, nil
+		},
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			deleted = append(deleted, deleteURL)
+			return nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("content"))
+	req.Header.Set("X-Short-Alias", "release-notes")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rec.Code)
+	}
+	if len(deleted) != 1 || deleted[0] != "https://transfer.sixtyfive.me/abc12/file.txt/del1" {
+		t.Errorf("expected the orphaned upload to be deleted, got %v", deleted)
+	}
+}
+
+func TestHandler_DeleteShortURL(t *testing.T) {
+	deleteUC := &mockDeleteShortURL{
+		executeFunc: func(ctx context.Context, token, deleteToken string) error {
+			switch {
+			case token != "xyz1":
+				return repository.ErrNotFound
+			case deleteToken != "s3cr3t":
+				return usecase.ErrInvalidDeleteToken
+			}
+			return nil
+		},
+	}
+
+	var proxyCalled bool
+	proxy := &mockBackendProxy{
+		proxyDeleteFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxyCalled = true
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDeleteShortURL(deleteUC))
+
+	tests := []struct {
+		name     string
+		path     string
+		header   string
+		expected int
+	}{
+		{"secret in path", "/xyz1/s3cr3t", "", http.StatusNoContent},
+		{"secret in header", "/xyz1", "s3cr3t", http.StatusNoContent},
+		{"wrong secret", "/xyz1/wrong", "", http.StatusForbidden},
+		{"missing secret", "/xyz1", "", http.StatusForbidden},
+		{"unknown token", "/nope/s3cr3t", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("X-Delete-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+
+	if proxyCalled {
+		t.Error("proxy should NOT be called for short delete links")
+	}
+}
+
+func TestHandler_DeleteShortURL_BackendError_ReturnsBadGateway(t *testing.T) {
+	deleteUC := &mockDeleteShortURL{
+		executeFunc: func(ctx context.Context, token, deleteToken string) error {
+			return usecase.ErrFileDeletion
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, &mockResolveShortURL{}, &mockBackendProxy{}, "https://transfer.sixtyfive.me",
+		handler.WithDeleteShortURL(deleteUC))
+
+	req := httptest.NewRequest(http.MethodDelete, "/xyz1/s3cr3t", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", rec.Code)
+	}
+}
+
+func TestHandler_Redirect_Success(t *testing.T) {
+	fullURL := "https://transfer.sixtyfive.me/abc12/file.txt"
+
+	createUC := &mockCreateShortURL{}
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token == "xyz1" {
+				return &entity.ShortURL{Token: token, FullURL: fullURL}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+	proxy := &mockBackendProxy{}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Errorf("expected status 307, got %d", rec.Code)
+	}
+
+	location := rec.Header().Get("Location")
+	if location != fullURL {
+		t.Errorf("expected Location %s, got %s", fullURL, location)
+	}
+}
+
+func TestHandler_UnknownToken_ProxiesToBackend(t *testing.T) {
+	// If short token not found, proxy to backend (might be a full URL token)
+	createUC := &mockCreateShortURL{}
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	var proxyCalled bool
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxyCalled = true
+			w.WriteHeader(http.StatusOK)
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodGet, "/abc12", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if !proxyCalled {
+		t.Error("expected proxy to be called for unknown token")
+	}
+}
+
+func TestHandler_ExpiredToken_ReturnsGone(t *testing.T) {
+	createUC := &mockCreateShortURL{}
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return nil, usecase.ErrExpired
+		},
+	}
+
+	var proxyCalled bool
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxyCalled = true
+		},
+	}
+
+	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusGone {
+		t.Errorf("expected status 410, got %d", rec.Code)
+	}
+	if proxyCalled {
+		t.Error("proxy should NOT be called for an expired token")
+	}
+}
+
+func TestHandler_Variant_Redirects(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token == "xyz1" {
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/build.log"}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, &mockBackendProxy{}, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/xyz1/inline", "https://transfer.sixtyfive.me/inline/abc12/build.log"},
+		{"/xyz1/get", "https://transfer.sixtyfive.me/get/abc12/build.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTemporaryRedirect {
+				t.Errorf("expected status 307, got %d", rec.Code)
+			}
+			if location := rec.Header().Get("Location"); location != tt.location {
+				t.Errorf("expected Location %s, got %s", tt.location, location)
+			}
+		})
+	}
+}
+
+func TestHandler_Variant_RawProxiesInlineForm(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/build.log"}, nil
+		},
+	}
+
+	var proxiedPath string
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxiedPath = r.URL.Path
+			w.WriteHeader(http.StatusOK)
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodGet, "/xyz1/raw", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if proxiedPath != "/inline/abc12/build.log" {
+		t.Errorf("expected proxied path /inline/abc12/build.log, got %s", proxiedPath)
+	}
+}
+
+func TestHandler_Variant_UnknownTokenProxiesOriginalPath(t *testing.T) {
+	// "/abc12/inline" may be a transfer.sh file literally named "inline"
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return nil, errors.New("not found")
+		},
+	}
+
+	var proxiedPath string
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxiedPath = r.URL.Path
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodGet, "/abc12/inline", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if proxiedPath != "/abc12/inline" {
+		t.Errorf("expected proxied path /abc12/inline, got %s", proxiedPath)
+	}
+}
+
+func TestHandler_ProxyMode_StreamsFile(t *testing.T) {
+	var receivedPath, receivedRange string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedRange = r.Header.Get("Range")
+		w.Header().Set("Content-Range", "bytes 0-3/12")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte("file"))
+	}))
+	defer backend.Close()
+
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+		},
+	}
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithResolveMode(handler.ResolveModeProxy))
+
+	req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+	req.Header.Set("Range", "bytes=0-3")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if receivedPath != "/abc12/file.txt" {
+		t.Errorf("expected backend path /abc12/file.txt, got %s", receivedPath)
+	}
+	if receivedRange != "bytes=0-3" {
+		t.Errorf("expected Range to be forwarded, got %q", receivedRange)
+	}
+	if rec.Code != http.StatusPartialContent {
+		t.Errorf("expected status 206, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 0-3/12" {
+		t.Errorf("expected Content-Range from backend, got %q", got)
+	}
+}
+
+func TestHandler_ProxyMode_PassesNotModified(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+		},
+	}
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithResolveMode(handler.ResolveModeProxy))
+
+	req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", rec.Code)
+	}
+}
+
+func TestHandler_ResolveMode_PerRequestOverride(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		mode     handler.ResolveMode
+		target   string
+		redirect bool
+	}{
+		{"proxy mode forced to redirect", handler.ResolveModeProxy, "/xyz1?redirect=1", true},
+		{"redirect mode forced to proxy", handler.ResolveModeRedirect, "/xyz1?redirect=0", false},
+		{"proxy mode default", handler.ResolveModeProxy, "/xyz1", false},
+		{"redirect mode default", handler.ResolveModeRedirect, "/xyz1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var proxyCalled bool
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					proxyCalled = true
+					w.WriteHeader(http.StatusOK)
+				},
+			}
+
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithResolveMode(tt.mode))
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if tt.redirect && rec.Code != http.StatusTemporaryRedirect {
+				t.Errorf("expected status 307, got %d", rec.Code)
+			}
+			if proxyCalled == tt.redirect {
+				t.Errorf("expected proxyCalled=%v, got %v", !tt.redirect, proxyCalled)
+			}
+		})
+	}
+}
+
+func TestHandler_Head_ShortLinkReturnsBackendMetadata(t *testing.T) {
+	var receivedMethod, receivedPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		receivedPath = r.URL.Path
+		w.Header().Set("Content-Length", "1048576")
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="build.zip"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{
+				Token:     token,
+				FullURL:   "https://transfer.sixtyfive.me/abc12/build.zip",
+				ExpiresAt: expiresAt,
+			}, nil
+		},
+	}
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	// Redirect mode must not apply to HEAD
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodHead, "/xyz1", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if receivedMethod != http.MethodHead {
+		t.Errorf("expected HEAD to reach the backend, got %s", receivedMethod)
+	}
+	if receivedPath != "/abc12/build.zip" {
+		t.Errorf("expected backend path /abc12/build.zip, got %s", receivedPath)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "1048576" {
+		t.Errorf("expected Content-Length 1048576, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("expected Content-Type application/zip, got %q", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="build.zip"` {
+		t.Errorf("expected Content-Disposition from backend, got %q", got)
+	}
+	if got := rec.Header().Get("X-Short-Expires"); got != "Wed, 02 Jan 2030 03:04:05 GMT" {
+		t.Errorf("expected X-Short-Expires, got %q", got)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected no body for HEAD, got %q", rec.Body.String())
+	}
+}
+
+func TestHandler_Head_FullPathProxiesHead(t *testing.T) {
+	var receivedMethod string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	resolveUC := &mockResolveShortURL{}
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodHead, "/abc12/file.txt", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+	if receivedMethod != http.MethodHead {
+		t.Errorf("expected HEAD to reach the backend, got %s", receivedMethod)
+	}
+}
+
+func TestParseResolveMode(t *testing.T) {
+	if mode, err := handler.ParseResolveMode("proxy"); err != nil || mode != handler.ResolveModeProxy {
+		t.Errorf("expected proxy mode, got %q (%v)", mode, err)
+	}
+	if _, err := handler.ParseResolveMode("teleport"); err == nil {
+		t.Error("expected error for unknown resolve mode")
+	}
+}
+
+func TestHandler_Bundle_ArchiveFormats(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			switch token {
+			case "bund":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/zip/abc12/a.log,def34/b.log"}, nil
+			case "xyz1":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/a.log"}, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+
+	var proxiedPath string
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxiedPath = r.URL.Path
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/bund", "https://transfer.sixtyfive.me/zip/abc12/a.log,def34/b.log"},
+		{"/bund.zip", "https://transfer.sixtyfive.me/zip/abc12/a.log,def34/b.log"},
+		{"/bund.tar", "https://transfer.sixtyfive.me/tar/abc12/a.log,def34/b.log"},
+		{"/bund.tar.gz", "https://transfer.sixtyfive.me/tar.gz/abc12/a.log,def34/b.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTemporaryRedirect {
+				t.Errorf("expected status 307, got %d", rec.Code)
+			}
+			if location := rec.Header().Get("Location"); location != tt.location {
+				t.Errorf("expected Location %s, got %s", tt.location, location)
+			}
+		})
+	}
+
+	// Archive suffixes on a single-file link fall through to the backend
+	req := httptest.NewRequest(http.MethodGet, "/xyz1.zip", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if proxiedPath != "/xyz1.zip" {
+		t.Errorf("expected /xyz1.zip to be proxied, got %q", proxiedPath)
+	}
+}
+
+func TestHandler_Upload_JSONResponse(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			shortURL := &entity.ShortURL{
+				Token:       "xyz1",
+				FullURL:     input.FullURL,
+				DeleteToken: input.DeleteToken,
+				CreatedAt:   createdAt,
+			}
+			shortURL.ExpireAfterDays(input.MaxDays)
+			return shortURL, nil
+		},
+	}
+	remaining := 3
+	var uploadPath string
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			uploadPath = r.URL.Path
+			return handler.UploadResult{Files: []handler.UploadedFile{{
+				FullURL:   "https://transfer.sixtyfive.me/abc12/file.txt",
+				DeleteURL: "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t",
+			}}}, nil
+		},
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "file.txt", Size: 12, ContentType: "text/plain", RemainingDownloads: &remaining}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{"accept header", "/file.txt", "application/json"},
+		{"api prefix", "/api/v1/file.txt", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader("file content"))
+			req.Header.Set("Max-Days", "1")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if uploadPath != "/file.txt" {
+				t.Errorf("expected backend upload path /file.txt, got %s", uploadPath)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("expected JSON response, got %q", got)
+			}
+
+			var doc struct {
+				Files []map[string]any `json:"files"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if len(doc.Files) != 1 {
+				t.Fatalf("expected 1 file, got %d", len(doc.Files))
+			}
+			expected := map[string]any{
+				"token":               "xyz1",
+				"short_url":           "https://transfer.sixtyfive.me/xyz1",
+				"full_url":            "https://transfer.sixtyfive.me/abc12/file.txt",
+				"delete_url":          "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t",
+				"short_delete_url":    "https://transfer.sixtyfive.me/xyz1/s3cr3t",
+				"filename":            "file.txt",
+				"size":                float64(12),
+				"content_type":        "text/plain",
+				"created_at":          "2026-01-02T03:04:05Z",
+				"expires_at":          "2026-01-03T03:04:05Z",
+				"remaining_downloads": float64(3),
+			}
+			for key, want := range expected {
+				if got := doc.Files[0][key]; got != want {
+					t.Errorf("expected %s=%v, got %v", key, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_LinkDocument(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			switch token {
+			case "xyz1":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t"}, nil
+			case "old1":
+				return nil, usecase.ErrExpired
+			case "lim1":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", MaxDownloads: 1, Downloads: 1}, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+	proxy := &mockBackendProxy{
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "file.txt", Size: 12}, nil
+		},
+	}
+	downloadsUC := &mockConsumeDownload{
+		executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			return usecase.ErrExpired
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDownloadLimits(downloadsUC),
+	)
+
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		expected int
+	}{
+		{"api endpoint", "/api/v1/links/xyz1", "", http.StatusOK},
+		{"accept header on short link", "/xyz1", "application/json", http.StatusOK},
+		{"unknown link", "/api/v1/links/nope", "", http.StatusNotFound},
+		{"expired link", "/api/v1/links/old1", "", http.StatusGone},
+		{"expired variant", "/old1/get", "application/json", http.StatusGone},
+		{"expired archive", "/old1.zip", "application/json", http.StatusGone},
+		{"expired qr code", "/old1.png", "application/json", http.StatusGone},
+		{"used up limited link", "/lim1/raw", "application/json", http.StatusGone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			var doc map[string]any
+			if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if tt.expected != http.StatusOK {
+				if doc["error"] == nil {
+					t.Error("expected error document")
+				}
+				return
+			}
+			if doc["full_url"] != "https://transfer.sixtyfive.me/abc12/file.txt" || doc["size"] != float64(12) {
+				t.Errorf("unexpected link document %v", doc)
+			}
+			if _, ok := doc["short_delete_url"]; ok {
+				t.Error("link document must not leak the deletion secret")
+			}
+		})
+	}
+}
+
+func TestHandler_Info(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			switch token {
+			case "xyz1":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", CreatedAt: createdAt}, nil
+			case "old1":
+				return nil, usecase.ErrExpired
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+	remaining := 2
+	var proxiedPath string
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxiedPath = r.URL.Path
+		},
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "file.txt", Size: 1536, ContentType: "text/plain", RemainingDownloads: &remaining}, nil
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		name           string
+		path           string
+		accept         string
+		expectedStatus int
+		expectedBody   []string
+		expectedProxy  string
+	}{
+		{
+			name:           "plus suffix as text",
+			path:           "/xyz1+",
+			expectedStatus: http.StatusOK,
+			expectedBody: []string{
+				"Target:               https://transfer.sixtyfive.me/abc12/file.txt",
+				"Filename:             file.txt",
+				"Size:                 1.5 KiB",
+				"Created:              2026-01-02T03:04:05Z",
+				"Remaining downloads:  2",
+			},
+		},
+		{
+			name:           "info path as JSON",
+			path:           "/xyz1/info",
+			accept:         "application/json",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"filename":"file.txt"`, `"size":1536`, `"remaining_downloads":2`},
+		},
+		{
+			name:           "expired link",
+			path:           "/old1+",
+			expectedStatus: http.StatusGone,
+		},
+		{
+			name:           "unknown token falls through to backend",
+			path:           "/abc12/info",
+			expectedStatus: http.StatusOK,
+			expectedProxy:  "/abc12/info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxiedPath = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			for _, want := range tt.expectedBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("expected body to contain %q, got:\n%s", want, rec.Body.String())
+				}
+			}
+			if proxiedPath != tt.expectedProxy {
+				t.Errorf("expected proxied path %q, got %q", tt.expectedProxy, proxiedPath)
+			}
+		})
+	}
+}
+
+func TestHandler_LandingPage(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/cat.png"}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "cat <1>.png", Size: 2048, ContentType: "image/png"}, nil
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	t.Run("browser gets landing page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("expected HTML, got %q", rec.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(rec.Header().Get("Vary"), "Accept") {
+			t.Error("expected Vary: Accept")
+		}
+		body := rec.Body.String()
+		for _, want := range []string{
+			`<meta property="og:title" content="cat &lt;1&gt;.png">`,
+			`<meta property="og:description" content="2.0 KiB · image/png">`,
+			`<meta property="og:image" content="https://transfer.sixtyfive.me/xyz1/raw">`,
+			`<meta name="twitter:card" content="summary_large_image">`,
+			`<a class="download" href="https://transfer.sixtyfive.me/xyz1/get">`,
+			`<img src="https://transfer.sixtyfive.me/xyz1/raw"`,
+		} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected page to contain %q, got:\n%s", want, body)
+			}
+		}
+	})
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+	}{
+		{"curl keeps redirect", "/xyz1", "*/*"},
+		{"explicit redirect override", "/xyz1?redirect=1", "text/html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusTemporaryRedirect {
+				t.Errorf("expected status 307, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_QRCode(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			switch token {
+			case "xyz1":
+				return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+			case "old1":
+				return nil, usecase.ErrExpired
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+	var proxiedPath string
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			proxiedPath = r.URL.Path
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me")
+
+	tests := []struct {
+		name                string
+		target              string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedPrefix      string
+		expectedProxy       string
+	}{
+		{"png suffix", "/xyz1.png", "", http.StatusOK, "image/png", "\x89PNG", ""},
+		{"svg format", "/xyz1/qr?format=svg", "", http.StatusOK, "image/svg+xml", "<svg", ""},
+		{"browser gets png", "/xyz1/qr", "text/html", http.StatusOK, "image/png", "\x89PNG", ""},
+		{"curl gets terminal blocks", "/xyz1/qr", "*/*", http.StatusOK, "text/plain; charset=utf-8", "\x1b[30;47m", ""},
+		{"expired link", "/old1.png", "", http.StatusGone, "", "", ""},
+		{"unknown token falls through to backend", "/logo.png", "", http.StatusOK, "", "", "/logo.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxiedPath = ""
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedContentType != "" && rec.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.expectedContentType, rec.Header().Get("Content-Type"))
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.expectedPrefix) {
+				t.Errorf("expected body to start with %q", tt.expectedPrefix)
+			}
+			if proxiedPath != tt.expectedProxy {
+				t.Errorf("expected proxied path %q, got %q", tt.expectedProxy, proxiedPath)
+			}
+		})
+	}
+}
+
+func TestHandler_Upload_QRCode(t *testing.T) {
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
+		},
+	}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+
+	t.Run("qr flag appends terminal code", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/file.txt?qr=1", strings.NewReader("file content"))
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		first, rest, _ := strings.Cut(rec.Body.String(), "\n")
+		if first != "https://transfer.sixtyfive.me/xyz1" {
+			t.Errorf("expected link on first line, got %q", first)
+		}
+		if !strings.Contains(rest, "\x1b[30;47m") {
+			t.Error("expected terminal QR code below the link")
+		}
+	})
+
+	t.Run("browser gets page with QR", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+		req.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+			t.Errorf("expected HTML, got %q", rec.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(body, `<a href="https://transfer.sixtyfive.me/xyz1">`) || !strings.Contains(body, "<svg") {
+			t.Errorf("expected link and inline QR code, got:\n%s", body)
+		}
+	})
+}
+
+type mockHitRecorder struct {
+	hits []entity.Hit
+}
+
+func (m *mockHitRecorder) Record(hit entity.Hit) {
+	m.hits = append(m.hits, hit)
+}
+
+type mockLinkStats struct {
+	executeFunc func(ctx context.Context, token string) (*entity.LinkStats, error)
+}
+
+func (m *mockLinkStats) Execute(ctx context.Context, token string) (*entity.LinkStats, error) {
+	return m.executeFunc(ctx, token)
+}
+
+func TestHandler_RecordsHits(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {},
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{}, nil
+		},
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		target           string
+		userAgent        string
+		referer          string
+		accept           string
+		expectedHits     int
+		expectedReferrer string
+		expectedClient   string
+	}{
+		{"curl download", http.MethodGet, "/xyz1", "curl/8.5.0", "", "", 1, "direct", entity.ClientCLI},
+		{"browser from chat", http.MethodGet, "/xyz1/get", "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", "https://app.slack.com/client/T1/C2", "", 1, "app.slack.com", entity.ClientBrowser},
+		{"link unfurler", http.MethodGet, "/xyz1", "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", "", "", 1, "direct", entity.ClientBot},
+		{"head is not a download", http.MethodHead, "/xyz1", "curl/8.5.0", "", "", 0, "", ""},
+		{"metadata is not a download", http.MethodGet, "/xyz1+", "curl/8.5.0", "", "", 0, "", ""},
+		{"landing page is not a download", http.MethodGet, "/xyz1", "Mozilla/5.0", "", "text/html", 0, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := &mockHitRecorder{}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithHitRecorder(hits),
+			)
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if len(hits.hits) != tt.expectedHits {
+				t.Fatalf("expected %d hits, got %d", tt.expectedHits, len(hits.hits))
+			}
+			if tt.expectedHits == 0 {
+				return
+			}
+			hit := hits.hits[0]
+			if hit.Token != "xyz1" || hit.At.IsZero() {
+				t.Errorf("unexpected hit %+v", hit)
+			}
+			if hit.Referrer != tt.expectedReferrer {
+				t.Errorf("expected referrer %q, got %q", tt.expectedReferrer, hit.Referrer)
+			}
+			if hit.Client != tt.expectedClient {
+				t.Errorf("expected client %q, got %q", tt.expectedClient, hit.Client)
+			}
+		})
+	}
+}
+
+func TestHandler_LinkStats(t *testing.T) {
+	lastAccess := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token != "xyz1" {
+				return nil, repository.ErrNotFound
+			}
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}, nil
+		},
+	}
+	statsUC := &mockLinkStats{
+		executeFunc: func(ctx context.Context, token string) (*entity.LinkStats, error) {
+			return &entity.LinkStats{
+				Token:      token,
+				Hits:       3,
+				LastAccess: lastAccess,
+				Referrers:  map[string]int64{"direct": 2, "app.slack.com": 1},
+				Clients:    map[string]int64{entity.ClientCLI: 3},
+				Days:       map[string]int64{"2026-01-02": 3},
+			}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "file.txt"}, nil
+		},
+	}
+
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithLinkStats(statsUC),
+	)
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedBody   []string
+	}{
+		{
+			name:           "stats breakdown",
+			target:         "/api/v1/links/xyz1/stats",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"clicks":3`, `"last_accessed_at":"2026-01-02T03:04:05Z"`, `"app.slack.com":1`, `"cli":3`, `"2026-01-02":3`},
+		},
+		{
+			name:           "clicks in link document",
+			target:         "/api/v1/links/xyz1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"clicks":3`, `"last_accessed_at":"2026-01-02T03:04:05Z"`},
+		},
+		{
+			name:           "clicks in info text",
+			target:         "/xyz1+",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"Clicks:       3", "Last access:  2026-01-02T03:04:05Z"},
+		},
+		{
+			name:           "unknown link",
+			target:         "/api/v1/links/nope/stats",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			for _, want := range tt.expectedBody {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("expected body to contain %q, got:\n%s", want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+type mockConsumeDownload struct {
+	executeFunc func(ctx context.Context, shortURL *entity.ShortURL) error
+	released    []*entity.ShortURL
+}
+
+func (m *mockConsumeDownload) Execute(ctx context.Context, shortURL *entity.ShortURL) error {
+	return m.executeFunc(ctx, shortURL)
+}
+
+func (m *mockConsumeDownload) Release(ctx context.Context, shortURL *entity.ShortURL) error {
+	m.released = append(m.released, shortURL)
+	return nil
+}
+
+func TestHandler_Upload_RecordsMaxDownloads(t *testing.T) {
+	var receivedInput usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			receivedInput = input
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL, MaxDownloads: input.MaxDownloads}, nil
+		},
+	}
+	var forwarded string
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			forwarded = r.Header.Get("Max-Downloads")
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
+		},
+	}
+	downloadsUC := &mockConsumeDownload{}
+
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDownloadLimits(downloadsUC),
+	)
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	req.Header.Set("Max-Downloads", "2")
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if receivedInput.MaxDownloads != 2 {
+		t.Errorf("expected MaxDownloads 2, got %d", receivedInput.MaxDownloads)
+	}
+	if forwarded != "" {
+		t.Errorf("expected Max-Downloads to stay at the shortener, backend got %q", forwarded)
+	}
+	if !strings.Contains(rec.Body.String(), `"full_url":"https://transfer.sixtyfive.me/abc12/file.txt"`) {
+		t.Errorf("expected uploader to see the full URL, got %s", rec.Body.String())
+	}
+}
+
+func TestHandler_DownloadLimit(t *testing.T) {
+	const slackbot = "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		userAgent        string
+		consumeErr       error
+		expectedStatus   int
+		expectedConsumed bool
+		expectedProxy    string
+		expectedHeader   string
+	}{
+		{"curl download is streamed and counted", http.MethodGet, "/xyz1", "curl/8.5.0", nil, http.StatusOK, true, "/abc12/file.txt", "1"},
+		{"variant download is counted", http.MethodGet, "/xyz1/get", "curl/8.5.0", nil, http.StatusOK, true, "/get/abc12/file.txt", "1"},
+		{"preview bot gets metadata", http.MethodGet, "/xyz1", slackbot, nil, http.StatusOK, false, "", ""},
+		{"head is not counted", http.MethodHead, "/xyz1", "curl/8.5.0", nil, http.StatusOK, false, "/abc12/file.txt", "2"},
+		{"last download already taken", http.MethodGet, "/xyz1", "curl/8.5.0", usecase.ErrExpired, http.StatusGone, true, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveUC := &mockResolveShortURL{
+				executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+					return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", MaxDownloads: 2}, nil
+				},
+			}
+			var consumed bool
+			downloadsUC := &mockConsumeDownload{
+				executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+					consumed = true
+					if tt.consumeErr != nil {
+						return tt.consumeErr
+					}
+					shortURL.Downloads++
+					return nil
+				},
+			}
+			var proxiedPath string
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					proxiedPath = r.URL.Path
+					w.WriteHeader(http.StatusOK)
+				},
+				statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+					return handler.FileInfo{Filename: "file.txt"}, nil
+				},
+			}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithDownloadLimits(downloadsUC),
+			)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if consumed != tt.expectedConsumed {
+				t.Errorf("expected consumed %v, got %v", tt.expectedConsumed, consumed)
+			}
+			if proxiedPath != tt.expectedProxy {
+				t.Errorf("expected proxied path %q, got %q", tt.expectedProxy, proxiedPath)
+			}
+			if got := rec.Header().Get("X-Short-Remaining-Downloads"); got != tt.expectedHeader {
+				t.Errorf("expected X-Short-Remaining-Downloads %q, got %q", tt.expectedHeader, got)
+			}
+			if tt.userAgent == slackbot && !strings.Contains(rec.Body.String(), `<meta property="og:title" content="file.txt">`) {
+				t.Errorf("expected landing page for preview bot, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_DownloadLimit_LastDownloadDeletesFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		downloads      int
+		expectedBurned bool
+	}{
+		{"download left keeps the file", 0, false},
+		{"last download deletes the file", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveUC := &mockResolveShortURL{
+				executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+					return &entity.ShortURL{
+						Token:        token,
+						FullURL:      "https://transfer.sixtyfive.me/abc12/file.txt",
+						DeleteToken:  "s3cr3t",
+						MaxDownloads: 2,
+						Downloads:    tt.downloads,
+					}, nil
+				},
+			}
+			downloadsUC := &mockConsumeDownload{
+				executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+					shortURL.Downloads++
+					return nil
+				},
+			}
+			burnUC := &mockBurnShortURL{}
+			var proxiedRange string
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					proxiedRange = r.Header.Get("Range")
+					w.WriteHeader(http.StatusOK)
+				},
+			}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithDownloadLimits(downloadsUC),
+				handler.WithBurnAfterReading(burnUC),
+			)
+
+			// A resumed download still counts, so it gets the whole file
+			req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+			req.Header.Set("User-Agent", "curl/8.5.0")
+			req.Header.Set("Range", "bytes=100-")
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, rec.Code)
+			}
+			if proxiedRange != "" {
+				t.Errorf("expected Range to be dropped, got %q", proxiedRange)
+			}
+			if got := len(burnUC.burned) == 1; got != tt.expectedBurned {
+				t.Errorf("expected burned %v, got %v", tt.expectedBurned, got)
+			}
+		})
+	}
+}
+
+func TestHandler_DownloadLimit_HidesFullURL(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", MaxDownloads: 3, Downloads: 1}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+			return handler.FileInfo{Filename: "file.txt"}, nil
+		},
+	}
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDownloadLimits(&mockConsumeDownload{}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/links/xyz1", nil)
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if strings.Contains(body, "abc12") {
+		t.Errorf("expected backend URL to stay hidden, got %s", body)
+	}
+	if !strings.Contains(body, `"remaining_downloads":2`) {
+		t.Errorf("expected 2 remaining downloads, got %s", body)
+	}
+}
+
+type mockVerifyPassword struct {
+	executeFunc func(ctx context.Context, shortURL *entity.ShortURL, password string) error
+}
+
+func (m *mockVerifyPassword) Execute(ctx context.Context, shortURL *entity.ShortURL, password string) error {
+	return m.executeFunc(ctx, shortURL, password)
+}
+
+func TestHandler_Upload_Password(t *testing.T) {
+	var receivedInput usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			receivedInput = input
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL}, nil
+		},
+	}
+	var uploaded bool
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			uploaded = true
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
+		},
+	}
+
+	t.Run("password is recorded", func(t *testing.T) {
+		h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me",
+			handler.WithPasswords(&mockVerifyPassword{}),
+		)
+		req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+		req.Header.Set("X-Short-Password", "s3cr3t")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if receivedInput.Password != "s3cr3t" {
+			t.Errorf("expected password to be passed on, got %q", receivedInput.Password)
+		}
+	})
+
+	t.Run("refused when passwords are disabled", func(t *testing.T) {
+		uploaded = false
+		h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me")
+		req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+		req.Header.Set("X-Short-Password", "s3cr3t")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		if uploaded {
+			t.Error("expected upload not to reach the backend")
+		}
+	})
+}
+
+func TestHandler_PasswordProtected(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", PasswordHash: "hash"}, nil
+		},
+	}
+	passwordUC := &mockVerifyPassword{
+		executeFunc: func(ctx context.Context, shortURL *entity.ShortURL, password string) error {
+			switch password {
+			case "":
+				return usecase.ErrPasswordRequired
+			case "s3cr3t":
+				return nil
+			case "flood":
+				return usecase.ErrTooManyAttempts
+			}
+			return usecase.ErrWrongPassword
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		password       string
+		form           string
+		accept         string
+		expectedStatus int
+		expectedProxy  string
+		expectedBody   string
+		expectedAuth   bool
+	}{
+		{name: "curl without password", method: http.MethodGet, path: "/xyz1", expectedStatus: http.StatusUnauthorized, expectedAuth: true},
+		{name: "curl with wrong password", method: http.MethodGet, path: "/xyz1", password: "guess", expectedStatus: http.StatusUnauthorized, expectedAuth: true},
+		{name: "curl with password streams file", method: http.MethodGet, path: "/xyz1", password: "s3cr3t", expectedStatus: http.StatusOK, expectedProxy: "/abc12/file.txt"},
+		{name: "variant needs password too", method: http.MethodGet, path: "/xyz1/get", expectedStatus: http.StatusUnauthorized, expectedAuth: true},
+		{name: "info needs password", method: http.MethodGet, path: "/xyz1+", expectedStatus: http.StatusUnauthorized, expectedAuth: true},
+		{name: "api needs password", method: http.MethodGet, path: "/api/v1/links/xyz1", expectedStatus: http.StatusUnauthorized, expectedAuth: true},
+		{name: "throttled", method: http.MethodGet, path: "/xyz1", password: "flood", expectedStatus: http.StatusTooManyRequests},
+		{name: "browser gets form", method: http.MethodGet, path: "/xyz1", accept: "text/html", expectedStatus: http.StatusUnauthorized, expectedBody: `<form method="post">`},
+		{name: "browser wrong password", method: http.MethodPost, path: "/xyz1", form: "password=guess", accept: "text/html", expectedStatus: http.StatusUnauthorized, expectedBody: "Wrong password"},
+		{name: "browser form streams file", method: http.MethodPost, path: "/xyz1", form: "password=s3cr3t", accept: "text/html", expectedStatus: http.StatusOK, expectedProxy: "/abc12/file.txt"},
+		{name: "metadata hides backend URL", method: http.MethodGet, path: "/api/v1/links/xyz1", password: "s3cr3t", expectedStatus: http.StatusOK, expectedBody: `"password_protected":true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var proxiedPath, proxiedMethod, proxiedAuth string
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					proxiedPath, proxiedMethod, proxiedAuth = r.URL.Path, r.Method, r.Header.Get("Authorization")
+					w.WriteHeader(http.StatusOK)
+				},
+				statFunc: func(ctx context.Context, fullURL string) (handler.FileInfo, error) {
+					return handler.FileInfo{Filename: "file.txt"}, nil
+				},
+			}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithPasswords(passwordUC),
+			)
+
+			var body io.Reader
+			if tt.form != "" {
+				body = strings.NewReader(tt.form)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, body)
+			if tt.form != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.password != "" {
+				req.SetBasicAuth("", tt.password)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if proxiedPath != tt.expectedProxy {
+				t.Errorf("expected proxied path %q, got %q", tt.expectedProxy, proxiedPath)
+			}
+			if proxiedPath != "" && (proxiedMethod != http.MethodGet || proxiedAuth != "") {
+				t.Errorf("expected a plain GET without credentials, got %s with Authorization %q", proxiedMethod, proxiedAuth)
+			}
+			if got := rec.Header().Get("WWW-Authenticate") != ""; got != tt.expectedAuth {
+				t.Errorf("expected WWW-Authenticate %v, got %v", tt.expectedAuth, got)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.expectedBody, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "abc12") {
+				t.Errorf("expected backend URL to stay hidden, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+type mockBurnShortURL struct {
+	burned []*entity.ShortURL
+}
+
+func (m *mockBurnShortURL) Execute(ctx context.Context, shortURL *entity.ShortURL) error {
+	m.burned = append(m.burned, shortURL)
+	return nil
+}
+
+func TestHandler_Upload_Once(t *testing.T) {
+	var receivedInput usecase.CreateShortURLInput
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			receivedInput = input
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL}, nil
+		},
+	}
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
+		},
+	}
+
+	tests := []struct {
+		name           string
+		opts           []handler.Option
+		expectedStatus int
+		expectedOnce   bool
+	}{
+		{"enabled", []handler.Option{handler.WithDownloadLimits(&mockConsumeDownload{}), handler.WithBurnAfterReading(&mockBurnShortURL{})}, http.StatusOK, true},
+		{"disabled", nil, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receivedInput = usecase.CreateShortURLInput{}
+			h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me", tt.opts...)
+			req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+			req.Header.Set("X-Short-Once", "1")
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if receivedInput.Once != tt.expectedOnce {
+				t.Errorf("expected Once %v, got %v", tt.expectedOnce, receivedInput.Once)
+			}
+		})
+	}
+}
+
+func TestHandler_Upload_OnceRejectsSeveralFiles(t *testing.T) {
+	created := false
+	createUC := &mockCreateShortURL{
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
+			created = true
+			return &entity.ShortURL{Token: "xyz1", FullURL: input.FullURL}, nil
+		},
+	}
+	var deleted []string
+	proxy := &mockBackendProxy{
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{
+				{FullURL: "https://transfer.sixtyfive.me/abc12/a.log", DeleteURL: "https://transfer.sixtyfive.me/abc12/a.log/del1"},
+				{FullURL: "https://transfer.sixtyfive.me/abc12/b.log", DeleteURL: "https://transfer.sixtyfive.me/abc12/b.log/del2"},
+			}}, nil
+		},
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			deleted = append(deleted, deleteURL)
+			return nil
+		},
+	}
+	h := handler.NewHandler(createUC, &mockResolveShortURL{}, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDownloadLimits(&mockConsumeDownload{}),
+		handler.WithBurnAfterReading(&mockBurnShortURL{}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("multipart"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	req.Header.Set("X-Short-Once", "1")
+	rec := httptest.NewRecorder()
+
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if created {
+		t.Error("expected no short links for a rejected upload")
+	}
+	if len(deleted) != 2 {
+		t.Errorf("expected both uploaded files to be deleted, got %v", deleted)
+	}
+}
+
+func TestHandler_OnceLink(t *testing.T) {
+	tests := []struct {
+		name             string
+		backendStatus    int
+		consumeErr       error
+		expectedStatus   int
+		expectedBurned   bool
+		expectedReleased bool
+	}{
+		{"first download burns the file", http.StatusOK, nil, http.StatusOK, true, false},
+		{"backend failure gives the download back", http.StatusNotFound, nil, http.StatusNotFound, false, true},
+		{"backend error gives the download back", http.StatusBadGateway, nil, http.StatusBadGateway, false, true},
+		{"second download is gone", http.StatusOK, usecase.ErrExpired, http.StatusGone, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveUC := &mockResolveShortURL{
+				executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+					shortURL := &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t"}
+					shortURL.MakeOneTime()
+					return shortURL, nil
+				},
+			}
+			downloadsUC := &mockConsumeDownload{
+				executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+					return tt.consumeErr
+				},
+			}
+			burnUC := &mockBurnShortURL{}
+			var proxiedPath, proxiedRange string
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					proxiedPath, proxiedRange = r.URL.Path, r.Header.Get("Range")
+					w.WriteHeader(tt.backendStatus)
+				},
+			}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithDownloadLimits(downloadsUC),
+				handler.WithBurnAfterReading(burnUC),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+			req.Header.Set("User-Agent", "curl/8.5.0")
+			req.Header.Set("Range", "bytes=100-")
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if got := len(burnUC.burned) == 1; got != tt.expectedBurned {
+				t.Errorf("expected burned %v, got %v", tt.expectedBurned, got)
+			}
+			if got := len(downloadsUC.released) == 1; got != tt.expectedReleased {
+				t.Errorf("expected released %v, got %v", tt.expectedReleased, got)
+			}
+			if tt.consumeErr == nil {
+				if proxiedPath != "/abc12/file.txt" {
+					t.Errorf("expected file to be streamed, got proxied path %q", proxiedPath)
+				}
+				if proxiedRange != "" {
+					t.Errorf("expected Range to be dropped, got %q", proxiedRange)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_FormPostToUnprotectedLink(t *testing.T) {
+	resolveUC := &mockResolveShortURL{
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			shortURL := &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t"}
+			shortURL.MakeOneTime()
+			return shortURL, nil
+		},
+	}
+	consumed := 0
+	downloadsUC := &mockConsumeDownload{
+		executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			consumed++
+			return nil
+		},
+	}
+	burnUC := &mockBurnShortURL{}
+	streamed := 0
+	proxy := &mockBackendProxy{
+		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+			streamed++
+			w.Write([]byte("file content"))
+		},
+	}
+	h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+		handler.WithDownloadLimits(downloadsUC),
+		handler.WithBurnAfterReading(burnUC),
+		handler.WithPasswords(&mockVerifyPassword{}),
+	)
+
+	for range 3 {
+		req := httptest.NewRequest(http.MethodPost, "/xyz1", strings.NewReader("password=guess"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+		}
+	}
+	if streamed != 0 || consumed != 0 || len(burnUC.burned) != 0 {
+		t.Errorf("expected no download, got %d streamed, %d consumed, %d burned", streamed, consumed, len(burnUC.burned))
+	}
+}
+
+func TestHandler_PasswordProtected_HeadDoesNotDownload(t *testing.T) {
+	tests := []struct {
+		name string
+		link func(shortURL *entity.ShortURL)
+	}{
+		{"one-time link", func(shortURL *entity.ShortURL) { shortURL.MakeOneTime() }},
+		{"limited link", func(shortURL *entity.ShortURL) { shortURL.MaxDownloads = 1 }},
+	}
 
-	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
-	rec := httptest.NewRecorder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolveUC := &mockResolveShortURL{
+				executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+					shortURL := &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t", PasswordHash: "hash"}
+					tt.link(shortURL)
+					return shortURL, nil
+				},
+			}
+			passwordUC := &mockVerifyPassword{
+				executeFunc: func(ctx context.Context, shortURL *entity.ShortURL, password string) error {
+					if password != "s3cr3t" {
+						return usecase.ErrWrongPassword
+					}
+					return nil
+				},
+			}
+			consumed := 0
+			downloadsUC := &mockConsumeDownload{
+				executeFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+					consumed++
+					return nil
+				},
+			}
+			burnUC := &mockBurnShortURL{}
+			var backendMethod string
+			proxy := &mockBackendProxy{
+				proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
+					backendMethod = r.Method
+					w.Header().Set("Content-Length", "12")
+				},
+			}
+			h := handler.NewHandler(&mockCreateShortURL{}, resolveUC, proxy, "https://transfer.sixtyfive.me",
+				handler.WithDownloadLimits(downloadsUC),
+				handler.WithBurnAfterReading(burnUC),
+				handler.WithPasswords(passwordUC),
+			)
+
+			req := httptest.NewRequest(http.MethodHead, "/xyz1", nil)
+			req.SetBasicAuth("", "s3cr3t")
+			req.Header.Set("User-Agent", "curl/8.5.0")
+			rec := httptest.NewRecorder()
 
-	h.ServeHTTP(rec, req)
+			h.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+			}
+			if backendMethod != http.MethodHead {
+				t.Errorf("expected the backend to get HEAD, got %q", backendMethod)
+			}
+			if consumed != 0 || len(burnUC.burned) != 0 {
+				t.Errorf("expected no download, got %d consumed, %d burned", consumed, len(burnUC.burned))
+			}
+		})
 	}
+}
 
-	body, _ := io.ReadAll(rec.Body)
-	expected := "https://transfer.sixtyfive.me/xyz1\n"
-	if string(body) != expected {
-		t.Errorf("expected body %q, got %q", expected, string(body))
-	}
+type mockBackup struct {
+	executeFunc func(ctx context.Context, w io.Writer) error
 }
 
-func TestHandler_Upload_POST_Success(t *testing.T) {
-	backendURL := "https://transfer.sixtyfive.me/abc12/file.txt"
+func (m *mockBackup) Execute(ctx context.Context, w io.Writer) error {
+	return m.executeFunc(ctx, w)
+}
 
-	createUC := &mockCreateShortURL{
-		executeFunc: func(ctx context.Context, fullURL string) (*entity.ShortURL, error) {
-			return &entity.ShortURL{
-				Token:     "xyz1",
-				FullURL:   fullURL,
-				CreatedAt: time.Now(),
-			}, nil
+func TestHandler_AdminBackup(t *testing.T) {
+	backupUC := &mockBackup{
+		executeFunc: func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "SQLite format 3\x00")
+			return err
 		},
 	}
-	resolveUC := &mockResolveShortURL{}
-	proxy := &mockBackendProxy{
-		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (string, error) {
-			return backendURL, nil
+
+	tests := []struct {
+		name           string
+		opts           []handler.Option
+		method         string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "disabled without admin token",
+			opts:           []handler.Option{handler.WithBackup(backupUC, "")},
+			method:         http.MethodGet,
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "missing token",
+			opts:           []handler.Option{handler.WithBackup(backupUC, "s3cr3t")},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong token",
+			opts:           []handler.Option{handler.WithBackup(backupUC, "s3cr3t")},
+			method:         http.MethodGet,
+			authorization:  "Bearer nope",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "upload to the admin path",
+			opts:           []handler.Option{handler.WithBackup(backupUC, "s3cr3t")},
+			method:         http.MethodPut,
+			authorization:  "Bearer s3cr3t",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "snapshot",
+			opts:           []handler.Option{handler.WithBackup(backupUC, "s3cr3t")},
+			method:         http.MethodGet,
+			authorization:  "Bearer s3cr3t",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "SQLite format 3\x00",
 		},
 	}
 
-	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHandler(&mockCreateShortURL{}, &mockResolveShortURL{}, &mockBackendProxy{}, "https://transfer.sixtyfive.me", tt.opts...)
 
-	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("file content"))
-	rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/v1/admin/backup", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
 
-	h.ServeHTTP(rec, req)
+			h.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Errorf("expected status 200, got %d", rec.Code)
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedBody == "" {
+				return
+			}
+			if rec.Body.String() != tt.expectedBody {
+				t.Errorf("unexpected body %q", rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/vnd.sqlite3" {
+				t.Errorf("expected sqlite content type, got %q", got)
+			}
+			if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="shortener-`) {
+				t.Errorf("unexpected Content-Disposition %q", got)
+			}
+		})
 	}
 }
 
-func TestHandler_Redirect_Success(t *testing.T) {
-	fullURL := "https://transfer.sixtyfive.me/abc12/file.txt"
-
-	createUC := &mockCreateShortURL{}
-	resolveUC := &mockResolveShortURL{
-		executeFunc: func(ctx context.Context, token string) (string, error) {
-			if token == "xyz1" {
-				return fullURL, nil
-			}
-			return "", errors.New("not found")
+func TestHandler_AdminBackup_Failure(t *testing.T) {
+	backupUC := &mockBackup{
+		executeFunc: func(ctx context.Context, w io.Writer) error {
+			return errors.New("disk full")
 		},
 	}
-	proxy := &mockBackendProxy{}
-
-	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+	h := handler.NewHandler(&mockCreateShortURL{}, &mockResolveShortURL{}, &mockBackendProxy{}, "https://transfer.sixtyfive.me",
+		handler.WithBackup(backupUC, "s3cr3t"),
+	)
 
-	req := httptest.NewRequest(http.MethodGet, "/xyz1", nil)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/backup", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t")
 	rec := httptest.NewRecorder()
 
 	h.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusTemporaryRedirect {
-		t.Errorf("expected status 307, got %d", rec.Code)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
 	}
-
-	location := rec.Header().Get("Location")
-	if location != fullURL {
-		t.Errorf("expected Location %s, got %s", fullURL, location)
+	if got := rec.Header().Get("Content-Disposition"); got != "" {
+		t.Errorf("expected no attachment on failure, got %q", got)
 	}
 }
 
-func TestHandler_UnknownToken_ProxiesToBackend(t *testing.T) {
-	// If short token not found, proxy to backend (might be a full URL token)
-	createUC := &mockCreateShortURL{}
-	resolveUC := &mockResolveShortURL{
-		executeFunc: func(ctx context.Context, token string) (string, error) {
-			return "", errors.New("not found")
-		},
-	}
+type mockCacheStats struct {
+	stats handler.CacheStats
+}
 
-	var proxyCalled bool
-	proxy := &mockBackendProxy{
-		proxyGetFunc: func(w http.ResponseWriter, r *http.Request) {
-			proxyCalled = true
-			w.WriteHeader(http.StatusOK)
+func (m *mockCacheStats) CacheStats() handler.CacheStats {
+	return m.stats
+}
+
+func TestHandler_AdminCacheStats(t *testing.T) {
+	cacheStats := &mockCacheStats{stats: handler.CacheStats{Hits: 7, NegativeHits: 2, Misses: 3, Evictions: 1, Entries: 4}}
+
+	tests := []struct {
+		name           string
+		opts           []handler.Option
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "disabled without admin token",
+			opts:           []handler.Option{handler.WithCacheStats(cacheStats, "")},
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "disabled without cache",
+			opts:           []handler.Option{handler.WithCacheStats(nil, "s3cr3t")},
+			authorization:  "Bearer s3cr3t",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "wrong token",
+			opts:           []handler.Option{handler.WithCacheStats(cacheStats, "s3cr3t")},
+			authorization:  "Bearer nope",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "counters",
+			opts:           []handler.Option{handler.WithCacheStats(cacheStats, "s3cr3t")},
+			authorization:  "Bearer s3cr3t",
+			expectedStatus: http.StatusOK,
 		},
 	}
 
-	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHandler(&mockCreateShortURL{}, &mockResolveShortURL{}, &mockBackendProxy{}, "https://transfer.sixtyfive.me", tt.opts...)
 
-	req := httptest.NewRequest(http.MethodGet, "/abc12", nil)
-	rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/cache", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
 
-	h.ServeHTTP(rec, req)
+			h.ServeHTTP(rec, req)
 
-	if !proxyCalled {
-		t.Error("expected proxy to be called for unknown token")
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var doc map[string]int64
+			if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+				t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+			}
+			want := map[string]int64{"hits": 7, "negative_hits": 2, "misses": 3, "evictions": 1, "entries": 4}
+			if !maps.Equal(doc, want) {
+				t.Errorf("expected %v, got %v", want, doc)
+			}
+		})
 	}
 }
 
@@ -227,8 +2544,8 @@ func TestHandler_FullPath_ProxiesToBackend(t *testing.T) {
 
 	createUC := &mockCreateShortURL{}
 	resolveUC := &mockResolveShortURL{
-		executeFunc: func(ctx context.Context, token string) (string, error) {
-			return "", errors.New("not found") // short token not found
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return nil, errors.New("not found") // short token not found
 		},
 	}
 	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
@@ -255,11 +2572,11 @@ func TestHandler_ShortToken_Redirects(t *testing.T) {
 
 	createUC := &mockCreateShortURL{}
 	resolveUC := &mockResolveShortURL{
-		executeFunc: func(ctx context.Context, token string) (string, error) {
+		executeFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
 			if token == "xyz1" {
-				return fullURL, nil
+				return &entity.ShortURL{Token: token, FullURL: fullURL}, nil
 			}
-			return "", errors.New("not found")
+			return nil, errors.New("not found")
 		},
 	}
 	proxy := &mockBackendProxy{}
@@ -416,8 +2733,8 @@ func TestHandler_Upload_ProxyError_ReturnsBadGateway(t *testing.T) {
 	createUC := &mockCreateShortURL{}
 	resolveUC := &mockResolveShortURL{}
 	proxy := &mockBackendProxy{
-		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (string, error) {
-			return "", errors.New("backend connection failed")
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{}, errors.New("backend connection failed")
 		},
 	}
 
@@ -436,14 +2753,14 @@ func TestHandler_Upload_ProxyError_ReturnsBadGateway(t *testing.T) {
 func TestHandler_Upload_CreateShortURLError_ReturnsInternalError(t *testing.T) {
 	// When creating short URL fails, should return 500 Internal Server Error
 	createUC := &mockCreateShortURL{
-		executeFunc: func(ctx context.Context, fullURL string) (*entity.ShortURL, error) {
+		executeFunc: func(ctx context.Context, input usecase.CreateShortURLInput) (*entity.ShortURL, error) {
 			return nil, errors.New("database error")
 		},
 	}
 	resolveUC := &mockResolveShortURL{}
 	proxy := &mockBackendProxy{
-		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (string, error) {
-			return "https://transfer.sixtyfive.me/abc12/file.txt", nil
+		proxyUploadFunc: func(w http.ResponseWriter, r *http.Request) (handler.UploadResult, error) {
+			return handler.UploadResult{Files: []handler.UploadedFile{{FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}}}, nil
 		},
 	}
 
@@ -467,7 +2784,7 @@ func TestHandler_MethodNotAllowed(t *testing.T) {
 
 	h := handler.NewHandler(createUC, resolveUC, proxy, "https://transfer.sixtyfive.me")
 
-	methods := []string{http.MethodDelete, http.MethodPatch, http.MethodOptions}
+	methods := []string{http.MethodPatch, http.MethodOptions}
 
 	for _, method := range methods {
 		t.Run(method, func(t *testing.T) {
@@ -502,7 +2819,7 @@ func TestTransferProxy_ProxyUpload_Success(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
 	rec := httptest.NewRecorder()
 
-	fullURL, err := proxy.ProxyUpload(rec, req)
+	result, err := proxy.ProxyUpload(rec, req)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -510,8 +2827,96 @@ func TestTransferProxy_ProxyUpload_Success(t *testing.T) {
 
 	// Should transform backend URL to public URL
 	expected := "https://transfer.sixtyfive.me/abc12/file.txt"
-	if fullURL != expected {
-		t.Errorf("expected %s, got %s", expected, fullURL)
+	if len(result.Files) != 1 || result.Files[0].FullURL != expected {
+		t.Errorf("expected single file %s, got %+v", expected, result.Files)
+	}
+}
+
+func TestTransferProxy_ProxyUpload_MultipleFiles(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// transfer.sh only reports the delete URL of the last file
+		w.Header().Set("X-Url-Delete", "http://backend:5327/def34/b.log/s3cr3t")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("http://backend:5327/abc12/a.log\nhttp://backend:5327/def34/b.log\n"))
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("multipart"))
+	rec := httptest.NewRecorder()
+
+	result, err := proxy.ProxyUpload(rec, req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []handler.UploadedFile{
+		{FullURL: "https://transfer.sixtyfive.me/abc12/a.log"},
+		{FullURL: "https://transfer.sixtyfive.me/def34/b.log", DeleteURL: "https://transfer.sixtyfive.me/def34/b.log/s3cr3t"},
+	}
+	if len(result.Files) != len(expected) {
+		t.Fatalf("expected %d files, got %d", len(expected), len(result.Files))
+	}
+	for i := range expected {
+		if result.Files[i] != expected[i] {
+			t.Errorf("file %d: expected %+v, got %+v", i, expected[i], result.Files[i])
+		}
+	}
+}
+
+func TestTransferProxy_ProxyUpload_StripsShortenerHeaders(t *testing.T) {
+	var receivedAlias, receivedMaxDays string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAlias = r.Header.Get("X-Short-Alias")
+		receivedMaxDays = r.Header.Get("Max-Days")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("http://backend:5327/abc12/file.txt\n"))
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	req.Header.Set("X-Short-Alias", "release-notes")
+	req.Header.Set("Max-Days", "3")
+	rec := httptest.NewRecorder()
+
+	if _, err := proxy.ProxyUpload(rec, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if receivedAlias != "" {
+		t.Errorf("expected X-Short-Alias not to reach the backend, got %q", receivedAlias)
+	}
+	if receivedMaxDays != "3" {
+		t.Errorf("expected Max-Days to reach the backend, got %q", receivedMaxDays)
+	}
+}
+
+func TestTransferProxy_ProxyUpload_CapturesDeleteURL(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Url-Delete", "http://backend:5327/abc12/file.txt/s3cr3t")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("http://backend:5327/abc12/file.txt\n"))
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("file content"))
+	rec := httptest.NewRecorder()
+
+	result, err := proxy.ProxyUpload(rec, req)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Delete URL should be rewritten to the public host as well
+	expected := "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t"
+	if result.Files[0].DeleteURL != expected {
+		t.Errorf("expected %s, got %s", expected, result.Files[0].DeleteURL)
 	}
 }
 
@@ -572,3 +2977,143 @@ func TestTransferProxy_ProxyGet_SetsPublicHostHeader(t *testing.T) {
 		t.Errorf("expected Host header %q, got %q", expectedHost, receivedHost)
 	}
 }
+
+func TestTransferProxy_DeleteFile(t *testing.T) {
+	var receivedMethod, receivedPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		receivedPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	err := proxy.DeleteFile(context.Background(), "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedMethod != http.MethodDelete {
+		t.Errorf("expected DELETE, got %s", receivedMethod)
+	}
+	if receivedPath != "/abc12/file.txt/s3cr3t" {
+		t.Errorf("expected path /abc12/file.txt/s3cr3t, got %s", receivedPath)
+	}
+}
+
+func TestTransferProxy_DeleteFile_BackendError(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	err := proxy.DeleteFile(context.Background(), "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t")
+
+	if err == nil {
+		t.Error("expected error when backend returns 500")
+	}
+}
+
+func TestTransferProxy_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer backend.Close()
+	defer close(release)
+
+	timeouts := handler.DefaultTimeouts()
+	timeouts.ResponseHeader = 50 * time.Millisecond
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me", handler.WithTimeouts(timeouts))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc12/file.txt", nil)
+	rec := httptest.NewRecorder()
+
+	proxy.ProxyGet(rec, req)
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502 when backend stalls, got %d", rec.Code)
+	}
+}
+
+func TestTransferProxy_StreamsBeyondResponseHeaderTimeout(t *testing.T) {
+	// Only the wait for headers is bounded; a slow body keeps streaming.
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 3; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer backend.Close()
+
+	timeouts := handler.DefaultTimeouts()
+	timeouts.ResponseHeader = 50 * time.Millisecond
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me", handler.WithTimeouts(timeouts))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc12/file.txt", nil)
+	rec := httptest.NewRecorder()
+
+	proxy.ProxyGet(rec, req)
+
+	if rec.Body.String() != "chunkchunkchunk" {
+		t.Errorf("expected full body, got %q", rec.Body.String())
+	}
+}
+
+func TestTransferProxy_ClientCancellationAbortsUpload(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPut, "/file.txt", strings.NewReader("content")).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	if _, err := proxy.ProxyUpload(rec, req); err == nil {
+		t.Error("expected error when the client went away")
+	}
+}
+
+func TestTransferProxy_Stat(t *testing.T) {
+	var receivedMethod string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedMethod = r.Method
+		w.Header().Set("Content-Length", "2048")
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Disposition", `inline; filename="shot.png"`)
+		w.Header().Set("X-Remaining-Downloads", "4")
+		w.Header().Set("X-Remaining-Days", "n/a")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	proxy := handler.NewTransferProxy(backend.URL, "https://transfer.sixtyfive.me")
+
+	info, err := proxy.Stat(context.Background(), "https://transfer.sixtyfive.me/abc12/shot.png")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedMethod != http.MethodHead {
+		t.Errorf("expected HEAD, got %s", receivedMethod)
+	}
+	if info.Filename != "shot.png" || info.Size != 2048 || info.ContentType != "image/png" {
+		t.Errorf("unexpected file info %+v", info)
+	}
+	if info.RemainingDownloads == nil || *info.RemainingDownloads != 4 {
+		t.Errorf("expected 4 remaining downloads, got %v", info.RemainingDownloads)
+	}
+	if info.RemainingDays != nil {
+		t.Errorf("expected no day limit for n/a, got %v", *info.RemainingDays)
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"transfer-shortener/usecase"
+)
+
+// "/{short}+" and "/{short}/info" describe a link without following it.
+const (
+	infoSuffix  = "+"
+	variantInfo = "info"
+)
+
+// infoToken returns the short token of an info path.
+func infoToken(path string) (string, bool) {
+	if short, ok := strings.CutSuffix(path, infoSuffix); ok && short != "" && !strings.Contains(short, "/") {
+		return short, true
+	}
+	if short, variant, ok := strings.Cut(path, "/"); ok && variant == variantInfo {
+		return short, true
+	}
+	return "", false
+}
+
+// serveInfo describes the link behind short as JSON or as plain text for
+// curl.
+func (h *Handler) serveInfo(w http.ResponseWriter, r *http.Request, short string) bool {
+	shortURL, err := h.resolveUC.Execute(r.Context(), short)
+	if errors.Is(err, usecase.ErrExpired) {
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	r, ok := h.unlock(w, r, shortURL)
+	if !ok {
+		return true
+	}
+
+	doc := h.linkDocument(r.Context(), shortURL)
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, doc)
+		return true
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", label, value)
+		}
+	}
+	row("Short URL", doc.ShortURL)
+	row("Target", doc.FullURL)
+	row("Filename", doc.Filename)
+	if doc.Size != nil {
+		row("Size", formatSize(*doc.Size))
+	}
+	row("Content-Type", doc.ContentType)
+	row("Created", doc.CreatedAt.Format(time.RFC3339))
+	if doc.ExpiresAt != nil {
+		row("Expires", doc.ExpiresAt.Format(time.RFC3339))
+	}
+	if doc.RemainingDays != nil {
+		row("Remaining days", fmt.Sprint(*doc.RemainingDays))
+	}
+	if doc.RemainingDownloads != nil {
+		row("Remaining downloads", fmt.Sprint(*doc.RemainingDownloads))
+	}
+	if doc.Clicks != nil {
+		row("Clicks", fmt.Sprint(*doc.Clicks))
+	}
+	if doc.LastAccessedAt != nil {
+		row("Last access", doc.LastAccessedAt.Format(time.RFC3339))
+	}
+	tw.Flush()
+	return true
+}
+
+// formatSize renders size in bytes with a binary unit, e.g. "1.5 MiB".
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
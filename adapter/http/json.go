@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/usecase"
+)
+
+// apiPrefix serves the JSON API: uploads below it answer in JSON,
+// "links/{short}" describes an existing link and "links/{short}/stats" breaks
+// down its hits.
+const (
+	apiPrefix   = "/api/v1/"
+	statsSuffix = "/stats"
+)
+
+type linkDocument struct {
+	Token              string     `json:"token"`
+	ShortURL           string     `json:"short_url"`
+	FullURL            string     `json:"full_url,omitempty"`
+	DeleteURL          string     `json:"delete_url,omitempty"`
+	ShortDeleteURL     string     `json:"short_delete_url,omitempty"`
+	QRURL              string     `json:"qr_url"`
+	Filename           string     `json:"filename,omitempty"`
+	Size               *int64     `json:"size,omitempty"`
+	ContentType        string     `json:"content_type,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	ExpiresAt          *time.Time `json:"expires_at,omitempty"`
+	RemainingDownloads *int       `json:"remaining_downloads,omitempty"`
+	RemainingDays      *int       `json:"remaining_days,omitempty"`
+	PasswordProtected  bool       `json:"password_protected,omitempty"`
+	BurnAfterReading   bool       `json:"burn_after_reading,omitempty"`
+	Clicks             *int64     `json:"clicks,omitempty"`
+	LastAccessedAt     *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+type uploadDocument struct {
+	Files  []linkDocument `json:"files"`
+	Bundle *linkDocument  `json:"bundle,omitempty"`
+}
+
+type errorDocument struct {
+	Error string `json:"error"`
+}
+
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("json encode error: %v", err)
+	}
+}
+
+// writeError answers in JSON when the client asked for it, as plain text
+// otherwise.
+func writeError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	if wantsJSON(r) {
+		writeJSON(w, status, errorDocument{Error: message})
+		return
+	}
+	http.Error(w, message, status)
+}
+
+func (h *Handler) handleAPI(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, apiPrefix)
+
+	r = r.Clone(r.Context())
+	r.Header.Set("Accept", "application/json")
+
+	switch {
+	case rest == "admin/backup":
+		h.handleBackup(w, r)
+	case rest == "admin/cache":
+		h.handleCacheStats(w, r)
+	case r.Method == http.MethodPut || r.Method == http.MethodPost:
+		r.URL.Path = "/" + rest
+		r.URL.RawPath = ""
+		h.handleUpload(w, r)
+	case isRead(r) && strings.HasPrefix(rest, "links/") && strings.HasSuffix(rest, statsSuffix):
+		h.handleStatsDocument(w, r, strings.TrimSuffix(strings.TrimPrefix(rest, "links/"), statsSuffix))
+	case isRead(r) && strings.HasPrefix(rest, "links/"):
+		h.handleLinkDocument(w, r, strings.TrimPrefix(rest, "links/"))
+	default:
+		writeError(w, r, "Not found", http.StatusNotFound)
+	}
+}
+
+func (h *Handler) handleLinkDocument(w http.ResponseWriter, r *http.Request, token string) {
+	shortURL, ok := h.resolveDocument(w, r, token)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.linkDocument(r.Context(), shortURL))
+}
+
+// resolveDocument resolves and unlocks token for an API document, answering
+// with the matching error when it cannot.
+func (h *Handler) resolveDocument(w http.ResponseWriter, r *http.Request, token string) (*entity.ShortURL, bool) {
+	shortURL, err := h.resolveUC.Execute(r.Context(), token)
+	switch {
+	case errors.Is(err, usecase.ErrExpired):
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return nil, false
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, usecase.ErrEmptyToken):
+		writeError(w, r, "Short URL not found", http.StatusNotFound)
+		return nil, false
+	case err != nil:
+		log.Printf("resolve error: %v", err)
+		writeError(w, r, "Failed to resolve short URL", http.StatusInternalServerError)
+		return nil, false
+	}
+	if _, ok := h.unlock(w, r, shortURL); !ok {
+		return nil, false
+	}
+	return shortURL, true
+}
+
+// linkDocument describes shortURL, filling file metadata from the backend when
+// it can be fetched. Archive bundles are generated on demand, so they have none.
+func (h *Handler) linkDocument(ctx context.Context, shortURL *entity.ShortURL) linkDocument {
+	doc := linkDocument{
+		Token:     shortURL.Token,
+		ShortURL:  fmt.Sprintf("%s/%s", h.publicURL, shortURL.Token),
+		FullURL:   shortURL.FullURL,
+		QRURL:     fmt.Sprintf("%s/%s%s", h.publicURL, shortURL.Token, qrSuffix),
+		CreatedAt: shortURL.CreatedAt.UTC(),
+	}
+	if !shortURL.ExpiresAt.IsZero() {
+		expiresAt := shortURL.ExpiresAt.UTC()
+		doc.ExpiresAt = &expiresAt
+	}
+	// Handing out the backend URL of a limited or protected link would bypass
+	// the limit or password
+	if remaining := shortURL.RemainingDownloads(); remaining >= 0 {
+		doc.FullURL = ""
+		doc.RemainingDownloads = &remaining
+	}
+	if shortURL.HasPassword() {
+		doc.FullURL = ""
+		doc.PasswordProtected = true
+	}
+	doc.BurnAfterReading = shortURL.BurnAfterReading
+
+	if h.statsUC != nil {
+		if stats, err := h.statsUC.Execute(ctx, shortURL.Token); err != nil {
+			log.Printf("stats error for %s: %v", shortURL.Token, err)
+		} else {
+			doc.Clicks = &stats.Hits
+			if !stats.LastAccess.IsZero() {
+				lastAccess := stats.LastAccess.UTC()
+				doc.LastAccessedAt = &lastAccess
+			}
+		}
+	}
+
+	if _, isArchive := archiveURL(shortURL.FullURL, archiveFormats[0]); isArchive {
+		return doc
+	}
+	info, err := h.proxy.Stat(ctx, shortURL.FullURL)
+	if err != nil {
+		log.Printf("stat error for %s: %v", shortURL.Token, err)
+		return doc
+	}
+	doc.Filename = info.Filename
+	if info.Size >= 0 {
+		doc.Size = &info.Size
+	}
+	doc.ContentType = info.ContentType
+	if doc.RemainingDownloads == nil {
+		doc.RemainingDownloads = info.RemainingDownloads
+	}
+	doc.RemainingDays = info.RemainingDays
+	return doc
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"rsc.io/qr"
+
+	"transfer-shortener/domain/entity"
+)
+
+// landingTemplate is shown to browsers opening a short link. The og: and
+// twitter: tags let chat apps unfurl the link with the file's details.
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.ShortURL}}">
+{{- if eq .Preview "image"}}
+<meta property="og:image" content="{{.PreviewURL}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.PreviewURL}}">
+{{- else}}
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<style>
+body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;padding:0 1rem;color:#222}
+dl{display:grid;grid-template-columns:max-content auto;gap:.25rem 1rem}
+dt{color:#666}
+.download{display:inline-block;margin:1rem 0;padding:.6rem 1.2rem;background:#2563eb;color:#fff;border-radius:.3rem;text-decoration:none}
+.preview img,.preview video,.preview iframe{max-width:100%;border:1px solid #ddd}
+.preview iframe{width:100%;height:24rem}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl>
+{{- if .Size}}<dt>Size</dt><dd>{{.Size}}</dd>{{end}}
+{{- if .ContentType}}<dt>Type</dt><dd>{{.ContentType}}</dd>{{end}}
+{{- if .Expires}}<dt>Expires</dt><dd>{{.Expires}}</dd>{{end}}
+{{- if .RemainingDownloads}}<dt>Downloads left</dt><dd>{{.RemainingDownloads}}</dd>{{end}}
+</dl>
+<a class="download" href="{{.DownloadURL}}">Download</a>
+<div class="preview">
+{{- if eq .Preview "image"}}
+<img src="{{.PreviewURL}}" alt="{{.Title}}">
+{{- else if eq .Preview "video"}}
+<video src="{{.PreviewURL}}" controls preload="metadata"></video>
+{{- else if eq .Preview "text"}}
+<iframe src="{{.PreviewURL}}" sandbox title="{{.Title}}"></iframe>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// uploadTemplate answers browser uploads with each link and its QR code, so
+// a phone can pick the file up straight from the screen.
+var uploadTemplate = template.Must(template.New("upload").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Uploaded</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;padding:0 1rem;color:#222}
+.link{display:flex;gap:1rem;align-items:center;margin-bottom:1.5rem}
+.link svg{width:10rem;height:10rem;flex:none}
+</style>
+</head>
+<body>
+{{- range .}}
+<div class="link">
+{{.QR}}
+<div>
+<a href="{{.ShortURL}}">{{.ShortURL}}</a>
+{{- if .DeleteURL}}<br><small>Delete: {{.DeleteURL}}</small>{{end}}
+</div>
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+type uploadLink struct {
+	ShortURL  string
+	DeleteURL string
+	QR        template.HTML
+}
+
+func (h *Handler) serveUploadPage(w http.ResponseWriter, shortURLs []*entity.ShortURL, bundle *entity.ShortURL) {
+	if bundle != nil {
+		shortURLs = append(shortURLs, bundle)
+	}
+	links := make([]uploadLink, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		link := uploadLink{ShortURL: fmt.Sprintf("%s/%s", h.publicURL, shortURL.Token)}
+		if shortURL.DeleteToken != "" {
+			link.DeleteURL = h.shortDeleteURL(shortURL)
+		}
+		if code, err := qr.Encode(link.ShortURL, qr.M); err == nil {
+			// qrSVG only emits markup built from numbers
+			link.QR = template.HTML(qrSVG(code))
+		}
+		links = append(links, link)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := uploadTemplate.Execute(w, links); err != nil {
+		log.Printf("upload page error: %v", err)
+	}
+}
+
+type landingPage struct {
+	Title              string
+	Description        string
+	ShortURL           string
+	DownloadURL        string
+	PreviewURL         string
+	Preview            string
+	Size               string
+	ContentType        string
+	Expires            string
+	RemainingDownloads string
+}
+
+func acceptsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// wantsLandingPage reports whether a GET comes from a browser. An explicit
+// ?redirect= still follows the link directly.
+func wantsLandingPage(r *http.Request) bool {
+	return r.Method == http.MethodGet && acceptsHTML(r) && !r.URL.Query().Has("redirect")
+}
+
+// serveLandingPage describes shortURL to a browser or link-preview bot. Links
+// with a download limit get no inline preview, since loading it would count
+// as a download.
+func (h *Handler) serveLandingPage(w http.ResponseWriter, r *http.Request, shortURL *entity.ShortURL) {
+	doc := h.linkDocument(r.Context(), shortURL)
+	_, isArchive := archiveURL(shortURL.FullURL, archiveFormats[0])
+
+	page := landingPage{
+		Title:       doc.Filename,
+		ShortURL:    doc.ShortURL,
+		DownloadURL: fmt.Sprintf("%s/%s/%s", h.publicURL, doc.Token, variantGet),
+		ContentType: doc.ContentType,
+	}
+	if isArchive {
+		page.Title = "Shared files"
+		page.DownloadURL = fmt.Sprintf("%s/%s.%s", h.publicURL, doc.Token, archiveFormats[0])
+	} else if shortURL.MaxDownloads <= 0 {
+		page.PreviewURL = fmt.Sprintf("%s/%s/%s", h.publicURL, doc.Token, variantRaw)
+		page.Preview = previewKind(doc.ContentType)
+	}
+	if page.Title == "" {
+		page.Title = doc.Token
+	}
+	if doc.Size != nil {
+		page.Size = formatSize(*doc.Size)
+	}
+	switch {
+	case doc.ExpiresAt != nil:
+		page.Expires = doc.ExpiresAt.Format(time.RFC1123)
+	case doc.RemainingDays != nil:
+		page.Expires = fmt.Sprintf("in %d days", *doc.RemainingDays)
+	}
+	if doc.RemainingDownloads != nil {
+		page.RemainingDownloads = fmt.Sprint(*doc.RemainingDownloads)
+	}
+
+	var details []string
+	for _, detail := range []string{page.Size, page.ContentType} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if page.Expires != "" {
+		details = append(details, "expires "+page.Expires)
+	}
+	page.Description = "Shared via transfer.sh"
+	if len(details) > 0 {
+		page.Description = strings.Join(details, " · ")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := landingTemplate.Execute(w, page); err != nil {
+		log.Printf("landing page error: %v", err)
+	}
+}
+
+// previewKind picks how the landing page embeds a file of contentType.
+func previewKind(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch mediaType = strings.TrimSpace(mediaType); {
+	case strings.HasPrefix(mediaType, "image/"):
+		return "image"
+	case strings.HasPrefix(mediaType, "video/"):
+		return "video"
+	case strings.HasPrefix(mediaType, "text/"), mediaType == "application/json":
+		return "text"
+	}
+	return ""
+}
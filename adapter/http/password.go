@@ -0,0 +1,149 @@
+package http
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/usecase"
+)
+
+// passwordTemplate asks browsers for the password of a protected link. The
+// form posts back to the link, which then streams the file.
+var passwordTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:24rem;margin:4rem auto;padding:0 1rem;color:#222}
+input,button{font:inherit;padding:.5rem;width:100%;box-sizing:border-box;margin-top:.5rem}
+.error{color:#b91c1c}
+</style>
+</head>
+<body>
+<h1>Password required</h1>
+{{- if .}}<p class="error">{{.}}</p>{{end}}
+<form method="post">
+<input type="password" name="password" autocomplete="current-password" autofocus required>
+<button type="submit">Download</button>
+</form>
+</body>
+</html>
+`))
+
+// isPasswordForm reports whether a POST carries the password form rather
+// than a multipart upload.
+func isPasswordForm(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return r.URL.Path != "/" && mediaType == "application/x-www-form-urlencoded"
+}
+
+// handlePasswordForm takes the password form posted to a protected link's
+// own URL. Links without a password are only ever read, so a form posted to
+// them is refused rather than treated as a download.
+func (h *Handler) handlePasswordForm(w http.ResponseWriter, r *http.Request) {
+	short, ok := formToken(strings.TrimPrefix(r.URL.Path, "/"))
+	if ok {
+		shortURL, err := h.resolveUC.Execute(r.Context(), short)
+		if errors.Is(err, usecase.ErrExpired) {
+			writeError(w, r, "Short URL has expired", http.StatusGone)
+			return
+		}
+		if err == nil && shortURL.HasPassword() {
+			h.handleGet(w, r)
+			return
+		}
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	writeError(w, r, "Method not allowed", http.StatusMethodNotAllowed)
+}
+
+// formToken returns the short token of the link pages that can show the
+// password form: the link itself, its variants, archives and info.
+func formToken(path string) (string, bool) {
+	if short, ok := infoToken(path); ok {
+		return short, true
+	}
+	if short, variant, ok := strings.Cut(path, "/"); ok {
+		return short, isLinkVariant(variant)
+	}
+	if short, format, ok := strings.Cut(path, "."); ok {
+		return short, isArchiveFormat(format)
+	}
+	return path, path != ""
+}
+
+// unlock checks the password of a protected link, taken from HTTP Basic auth
+// (any user name) or the password form. On failure it answers the request
+// and returns false. On success it returns a copy of r stripped of the
+// credentials, so they never reach the backend.
+func (h *Handler) unlock(w http.ResponseWriter, r *http.Request, shortURL *entity.ShortURL) (*http.Request, bool) {
+	if !shortURL.HasPassword() {
+		return r, true
+	}
+	if h.passwordUC == nil {
+		writeError(w, r, "Short URL is locked", http.StatusForbidden)
+		return r, false
+	}
+
+	_, password, _ := r.BasicAuth()
+	if r.Method == http.MethodPost {
+		password = r.PostFormValue("password")
+	}
+
+	err := h.passwordUC.Execute(r.Context(), shortURL, password)
+	switch {
+	case err == nil:
+		unlocked := r.Clone(r.Context())
+		unlocked.Header.Del("Authorization")
+		// The password form is a POST for the file; HEAD stays HEAD so it
+		// neither counts as a download nor burns the link
+		if r.Method == http.MethodPost {
+			unlocked.Method = http.MethodGet
+			unlocked.Body = http.NoBody
+			unlocked.ContentLength = 0
+			unlocked.Header.Del("Content-Type")
+			unlocked.Header.Del("Content-Length")
+		}
+		return unlocked, true
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		h.askPassword(w, r, "Too many wrong passwords, try again later", http.StatusTooManyRequests)
+	case errors.Is(err, usecase.ErrWrongPassword):
+		h.askPassword(w, r, "Wrong password", http.StatusUnauthorized)
+	case errors.Is(err, usecase.ErrPasswordRequired):
+		h.askPassword(w, r, "", http.StatusUnauthorized)
+	default:
+		log.Printf("password error for %s: %v", shortURL.Token, err)
+		writeError(w, r, "Internal error", http.StatusInternalServerError)
+	}
+	return r, false
+}
+
+// askPassword shows browsers the password form and challenges everyone else
+// for HTTP Basic credentials.
+func (h *Handler) askPassword(w http.ResponseWriter, r *http.Request, message string, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	if acceptsHTML(r) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		if err := passwordTemplate.Execute(w, message); err != nil {
+			log.Printf("password page error: %v", err)
+		}
+		return
+	}
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="short link", charset="UTF-8"`)
+	}
+	if message == "" {
+		message = "Password required"
+	}
+	writeError(w, r, message, status)
+}
@@ -1,41 +1,126 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// UploadedFile holds the public-facing URLs transfer.sh returned for a file.
+type UploadedFile struct {
+	FullURL string
+	// DeleteURL is empty when the backend did not send X-Url-Delete for it.
+	DeleteURL string
+}
+
+// UploadResult lists the uploaded files in the order the backend returned
+// them, which is the order they appeared in a multipart request.
+type UploadResult struct {
+	Files []UploadedFile
+}
+
+// maxUploadResponseSize caps the backend's upload reply, which only lists URLs.
+const maxUploadResponseSize = 1 << 20
+
+// Timeouts bound each phase of a backend round trip. Bodies are never capped:
+// transfers run as long as the client stays connected, and cancellation
+// follows the client request's context.
+type Timeouts struct {
+	Dial         time.Duration
+	TLSHandshake time.Duration
+	// ResponseHeader starts once the request body has been fully sent, so it
+	// does not limit how long an upload may take.
+	ResponseHeader time.Duration
+	IdleConn       time.Duration
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Dial:           10 * time.Second,
+		TLSHandshake:   10 * time.Second,
+		ResponseHeader: 2 * time.Minute,
+		IdleConn:       90 * time.Second,
+	}
+}
+
+// FileInfo is what the backend reports about a stored file without sending it.
+type FileInfo struct {
+	Filename    string
+	Size        int64
+	ContentType string
+	// RemainingDownloads and RemainingDays are nil when the backend reports no
+	// limit.
+	RemainingDownloads *int
+	RemainingDays      *int
+}
+
 type TransferProxy struct {
 	backendURL string
 	publicURL  string
+	timeouts   Timeouts
 	client     *http.Client
 }
 
-func NewTransferProxy(backendURL, publicURL string) *TransferProxy {
-	return &TransferProxy{
+// ProxyOption configures a TransferProxy.
+type ProxyOption func(*TransferProxy)
+
+func WithTimeouts(timeouts Timeouts) ProxyOption {
+	return func(p *TransferProxy) {
+		p.timeouts = timeouts
+	}
+}
+
+func NewTransferProxy(backendURL, publicURL string, opts ...ProxyOption) *TransferProxy {
+	p := &TransferProxy{
 		backendURL: backendURL,
 		publicURL:  publicURL,
-		client: &http.Client{
-			Timeout: 10 * time.Minute,
-		},
+		timeouts:   DefaultTimeouts(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.client = &http.Client{Transport: newTransport(p.timeouts)}
+	return p
+}
+
+func newTransport(timeouts Timeouts) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   timeouts.Dial,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeouts.TLSHandshake,
+		ResponseHeaderTimeout: timeouts.ResponseHeader,
+		IdleConnTimeout:       timeouts.IdleConn,
+		ExpectContinueTimeout: time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   16,
+		ForceAttemptHTTP2:     true,
 	}
 }
 
-func (p *TransferProxy) ProxyUpload(w http.ResponseWriter, r *http.Request) (string, error) {
+func (p *TransferProxy) ProxyUpload(w http.ResponseWriter, r *http.Request) (UploadResult, error) {
 	targetURL := p.backendURL + r.URL.Path
 
 	req, err := http.NewRequestWithContext(r.Context(), r.Method, targetURL, r.Body)
 	if err != nil {
-		return "", err
+		return UploadResult{}, err
 	}
 
 	req.ContentLength = r.ContentLength
 	for key, values := range r.Header {
-		if key == "Host" {
+		// X-Short-* headers are options for the shortener itself
+		if key == "Host" || strings.HasPrefix(key, "X-Short-") {
 			continue
 		}
 		for _, value := range values {
@@ -45,34 +130,155 @@ func (p *TransferProxy) ProxyUpload(w http.ResponseWriter, r *http.Request) (str
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", err
+		return UploadResult{}, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxUploadResponseSize))
 	if err != nil {
-		return "", err
+		return UploadResult{}, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("backend returned %d: %s", resp.StatusCode, string(body))
+		return UploadResult{}, fmt.Errorf("backend returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Transform internal backend URLs to public URLs
+	var deleteURLs []string
+	for _, raw := range resp.Header.Values("X-Url-Delete") {
+		deleteURL, err := p.toPublicURL(raw)
+		if err != nil {
+			return UploadResult{}, err
+		}
+		deleteURLs = append(deleteURLs, deleteURL)
+	}
+
+	// One URL per line, one line per uploaded file
+	var result UploadResult
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fullURL, err := p.toPublicURL(line)
+		if err != nil {
+			return UploadResult{}, err
+		}
+		result.Files = append(result.Files, UploadedFile{
+			FullURL:   fullURL,
+			DeleteURL: matchDeleteURL(fullURL, deleteURLs),
+		})
 	}
 
-	// Transform internal backend URL to public URL
+	if len(result.Files) == 0 {
+		return UploadResult{}, fmt.Errorf("backend returned no URL")
+	}
+	return result, nil
+}
+
+// matchDeleteURL picks the delete URL belonging to fullURL. transfer.sh may
+// send a single X-Url-Delete for a multi-file upload, so files without a
+// match simply have no delete URL.
+func matchDeleteURL(fullURL string, deleteURLs []string) string {
+	for _, deleteURL := range deleteURLs {
+		if strings.HasPrefix(deleteURL, fullURL+"/") {
+			return deleteURL
+		}
+	}
+	return ""
+}
+
+// ProxyGet forwards a GET or HEAD request to the backend.
+func (p *TransferProxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
+	method := http.MethodGet
+	if r.Method == http.MethodHead {
+		method = http.MethodHead
+	}
+	p.forward(w, r, method)
+}
+
+// ProxyDelete forwards a transfer.sh delete request (/{token}/{filename}/{deletion token}).
+func (p *TransferProxy) ProxyDelete(w http.ResponseWriter, r *http.Request) {
+	p.forward(w, r, http.MethodDelete)
+}
+
+// Stat asks the backend for a file's metadata with a HEAD request.
+func (p *TransferProxy) Stat(ctx context.Context, fullURL string) (FileInfo, error) {
+	parsed, err := url.Parse(fullURL)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, p.backendURL+parsed.EscapedPath(), nil)
+	if err != nil {
+		return FileInfo{}, err
+	}
 	publicParsed, _ := url.Parse(p.publicURL)
-	returnedURL, err := url.Parse(strings.TrimSpace(string(body)))
+	req.Host = publicParsed.Host
+
+	resp, err := p.client.Do(req)
 	if err != nil {
-		return "", err
+		return FileInfo{}, err
 	}
-	returnedURL.Scheme = publicParsed.Scheme
-	returnedURL.Host = publicParsed.Host
-	return returnedURL.String(), nil
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return FileInfo{}, fmt.Errorf("backend returned %d", resp.StatusCode)
+	}
+
+	info := FileInfo{
+		Filename:           path.Base(parsed.Path),
+		Size:               resp.ContentLength,
+		ContentType:        resp.Header.Get("Content-Type"),
+		RemainingDownloads: parseRemaining(resp.Header.Get("X-Remaining-Downloads")),
+		RemainingDays:      parseRemaining(resp.Header.Get("X-Remaining-Days")),
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		info.Filename = params["filename"]
+	}
+	return info, nil
 }
 
-func (p *TransferProxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
+// parseRemaining reads transfer.sh's X-Remaining-* headers, which are "n/a"
+// when the upload has no such limit.
+func parseRemaining(value string) *int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// DeleteFile removes a file from the backend given its public delete URL. A
+// file the backend no longer has counts as deleted.
+func (p *TransferProxy) DeleteFile(ctx context.Context, deleteURL string) error {
+	parsed, err := url.Parse(deleteURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, p.backendURL+parsed.Path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("backend returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *TransferProxy) forward(w http.ResponseWriter, r *http.Request, method string) {
 	targetURL := p.backendURL + r.URL.Path
 
-	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, targetURL, nil)
+	req, err := http.NewRequestWithContext(r.Context(), method, targetURL, nil)
 	if err != nil {
 		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
@@ -108,3 +314,15 @@ func (p *TransferProxy) ProxyGet(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(resp.StatusCode)
 	io.Copy(w, resp.Body)
 }
+
+// toPublicURL rewrites a URL returned by the backend onto the public host.
+func (p *TransferProxy) toPublicURL(raw string) (string, error) {
+	publicParsed, _ := url.Parse(p.publicURL)
+	returnedURL, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	returnedURL.Scheme = publicParsed.Scheme
+	returnedURL.Host = publicParsed.Host
+	return returnedURL.String(), nil
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"rsc.io/qr"
+
+	"transfer-shortener/usecase"
+)
+
+// "/{short}.png" and "/{short}/qr" render a QR code for the short link.
+const (
+	qrSuffix  = ".png"
+	variantQR = "qr"
+)
+
+// QR output formats selectable with ?format=.
+const (
+	qrFormatPNG  = "png"
+	qrFormatSVG  = "svg"
+	qrFormatANSI = "ansi"
+)
+
+// qrQuietZone is the blank border, in modules, scanners need around a code.
+const qrQuietZone = 4
+
+// qrToken returns the short token of a QR path and the format its suffix
+// implies ("" when only the query or Accept header decide).
+func qrToken(path string) (string, string, bool) {
+	if short, ok := strings.CutSuffix(path, qrSuffix); ok && short != "" && !strings.Contains(short, "/") {
+		return short, qrFormatPNG, true
+	}
+	if short, variant, ok := strings.Cut(path, "/"); ok && variant == variantQR {
+		return short, "", true
+	}
+	return "", "", false
+}
+
+// qrFormat applies ?format= first, then the path's implied format. Without
+// either, browsers and image clients get a PNG and curl gets terminal blocks.
+func qrFormat(r *http.Request, implied string) string {
+	switch format := r.URL.Query().Get("format"); format {
+	case qrFormatPNG, qrFormatSVG, qrFormatANSI:
+		return format
+	}
+	if implied != "" {
+		return implied
+	}
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "text/html") || strings.Contains(accept, "image/") {
+		return qrFormatPNG
+	}
+	return qrFormatANSI
+}
+
+// serveQR renders the QR code of short's public URL.
+func (h *Handler) serveQR(w http.ResponseWriter, r *http.Request, short, format string) bool {
+	shortURL, err := h.resolveUC.Execute(r.Context(), short)
+	if errors.Is(err, usecase.ErrExpired) {
+		writeError(w, r, "Short URL has expired", http.StatusGone)
+		return true
+	}
+	if err != nil {
+		return false
+	}
+
+	code, err := qr.Encode(fmt.Sprintf("%s/%s", h.publicURL, shortURL.Token), qr.M)
+	if err != nil {
+		log.Printf("qr error for %s: %v", shortURL.Token, err)
+		writeError(w, r, "Failed to render QR code", http.StatusInternalServerError)
+		return true
+	}
+
+	w.Header().Add("Vary", "Accept")
+	switch format {
+	case qrFormatPNG:
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(code.PNG())
+	case qrFormatSVG:
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(qrSVG(code)))
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(qrANSI(code)))
+	}
+	return true
+}
+
+// qrSVG draws code as one path of unit squares, scaled by the viewer.
+func qrSVG(code *qr.Code) string {
+	size := code.Size + 2*qrQuietZone
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, size, size)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+
+// qrANSI draws code for a terminal, two module rows per line using half
+// blocks. Colors are set explicitly so the code scans on dark and light
+// themes alike.
+func qrANSI(code *qr.Code) string {
+	const border = qrQuietZone / 2
+	var b strings.Builder
+	for y := -border; y < code.Size+border; y += 2 {
+		b.WriteString("\x1b[30;47m")
+		for x := -border; x < code.Size+border; x++ {
+			top, bottom := code.Black(x, y), code.Black(x, y+1)
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,281 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"transfer-shortener/adapter/sqlrow"
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotFound     = repository.ErrNotFound
+	ErrSchemaTooNew = repository.ErrSchemaTooNew
+)
+
+// uniqueViolation is the SQLSTATE of a duplicate key.
+const uniqueViolation = "23505"
+
+// migrationLock is the advisory lock key that serializes schema changes when
+// several replicas start at once.
+const migrationLock = 0x73686f7274 // "short"
+
+type Repository struct {
+	db *sql.DB
+}
+
+var _ repository.URLRepository = (*Repository)(nil)
+
+func NewRepository(dsn string) (*Repository, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Repository{db: db}, nil
+}
+
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+type migration struct {
+	version int
+	name    string
+	query   string
+}
+
+// migrations run in order inside the startup transaction. Replicas on an
+// older binary refuse a newer version, so add steps rather than changing
+// step 1, which also adopts databases created before schema_migrations.
+var migrations = []migration{
+	{version: 1, name: "create_schema", query: `
+		CREATE TABLE IF NOT EXISTS urls (
+			token TEXT PRIMARY KEY,
+			full_url TEXT NOT NULL,
+			created_at BIGINT NOT NULL,
+			expires_at BIGINT NOT NULL DEFAULT 0,
+			delete_token TEXT NOT NULL DEFAULT '',
+			max_downloads INTEGER NOT NULL DEFAULT 0,
+			downloads INTEGER NOT NULL DEFAULT 0,
+			password_hash TEXT NOT NULL DEFAULT '',
+			burn_after_reading BOOLEAN NOT NULL DEFAULT FALSE
+		);
+		CREATE INDEX IF NOT EXISTS idx_created_at ON urls(created_at);
+		CREATE INDEX IF NOT EXISTS idx_expires_at ON urls(expires_at);
+
+		CREATE TABLE IF NOT EXISTS link_stats (
+			token TEXT PRIMARY KEY,
+			hits BIGINT NOT NULL DEFAULT 0,
+			last_access BIGINT NOT NULL DEFAULT 0
+		);
+		CREATE TABLE IF NOT EXISTS link_hit_buckets (
+			token TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			hits BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (token, kind, bucket)
+		);
+	`},
+}
+
+// migrate applies the pending migrations in one transaction, recording each
+// in schema_migrations. The advisory lock lets only one replica at a time
+// do so.
+func migrate(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", migrationLock); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at BIGINT NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	var version int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version); err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].version
+	if version > latest {
+		return fmt.Errorf("%w: version %d, binary supports up to %d", ErrSchemaTooNew, version, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		if _, err := tx.Exec(m.query); err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.version, m.name, err)
+		}
+		_, err := tx.Exec(
+			"INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+			m.version, m.name, time.Now().Unix(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const insertURL = "INSERT INTO urls (" + sqlrow.URLColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+
+func (r *Repository) Save(ctx context.Context, shortURL *entity.ShortURL) error {
+	_, err := r.db.ExecContext(ctx, insertURL, sqlrow.InsertArgs(shortURL)...)
+	if isUniqueViolation(err) {
+		return repository.ErrDuplicateToken
+	}
+	return err
+}
+
+func (r *Repository) SaveAll(ctx context.Context, shortURLs []*entity.ShortURL) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertURL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, shortURL := range shortURLs {
+		if _, err := stmt.ExecContext(ctx, sqlrow.InsertArgs(shortURL)...); err != nil {
+			if isUniqueViolation(err) {
+				return repository.ErrDuplicateToken
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+func (r *Repository) FindByToken(ctx context.Context, token string) (*entity.ShortURL, error) {
+	shortURL, err := sqlrow.ScanURL(r.db.QueryRowContext(ctx,
+		"SELECT "+sqlrow.URLColumns+" FROM urls WHERE token = $1",
+		token,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return shortURL, err
+}
+
+// ConsumeDownload increments the counter only while it is below the limit,
+// so concurrent downloads on any replica can never overshoot it.
+func (r *Repository) ConsumeDownload(ctx context.Context, token string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE urls SET downloads = downloads + 1 WHERE token = $1 AND max_downloads > 0 AND downloads < max_downloads",
+		token,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrDownloadLimitReached
+	}
+	return nil
+}
+
+func (r *Repository) ReleaseDownload(ctx context.Context, token string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE urls SET downloads = downloads - 1 WHERE token = $1 AND downloads > 0",
+		token,
+	)
+	return err
+}
+
+func (r *Repository) Delete(ctx context.Context, token string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM urls WHERE token = $1", token)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	if err := deleteStats(ctx, tx, token); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteExpired also sweeps statistics whose link is gone, including hits
+// another replica flushed after the link was deleted.
+func (r *Repository) DeleteExpired(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+	query := "DELETE FROM urls WHERE (expires_at > 0 AND expires_at <= $1) OR (max_downloads > 0 AND downloads >= max_downloads)"
+	args := []any{now.Unix()}
+	if !createdBefore.IsZero() {
+		query += " OR created_at < $2"
+		args = append(args, createdBefore.Unix())
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := deleteStats(ctx, tx, ""); err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
+}
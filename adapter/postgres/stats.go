@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"transfer-shortener/adapter/sqlrow"
+	"transfer-shortener/domain/entity"
+)
+
+const (
+	upsertLinkStats = `INSERT INTO link_stats (token, hits, last_access) VALUES ($1, 1, $2)
+		ON CONFLICT (token) DO UPDATE SET hits = link_stats.hits + 1,
+		last_access = GREATEST(link_stats.last_access, excluded.last_access)`
+	upsertHitBucket = `INSERT INTO link_hit_buckets (token, kind, bucket, hits) VALUES ($1, $2, $3, 1)
+		ON CONFLICT (token, kind, bucket) DO UPDATE SET hits = link_hit_buckets.hits + 1`
+)
+
+func (r *Repository) RecordHits(ctx context.Context, hits []entity.Hit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statsStmt, err := tx.PrepareContext(ctx, upsertLinkStats)
+	if err != nil {
+		return err
+	}
+	defer statsStmt.Close()
+
+	bucketStmt, err := tx.PrepareContext(ctx, upsertHitBucket)
+	if err != nil {
+		return err
+	}
+	defer bucketStmt.Close()
+
+	for _, hit := range hits {
+		if _, err := statsStmt.ExecContext(ctx, hit.Token, hit.At.Unix()); err != nil {
+			return err
+		}
+		for _, bucket := range sqlrow.HitBuckets(hit) {
+			if _, err := bucketStmt.ExecContext(ctx, hit.Token, bucket[0], bucket[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) Stats(ctx context.Context, token string) (*entity.LinkStats, error) {
+	stats := &entity.LinkStats{
+		Token:     token,
+		Referrers: map[string]int64{},
+		Clients:   map[string]int64{},
+		Days:      map[string]int64{},
+	}
+
+	var lastAccess int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT hits, last_access FROM link_stats WHERE token = $1",
+		token,
+	).Scan(&stats.Hits, &lastAccess)
+	if errors.Is(err, sql.ErrNoRows) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	stats.LastAccess = sqlrow.FromUnix(lastAccess)
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT kind, bucket, hits FROM link_hit_buckets WHERE token = $1",
+		token,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := sqlrow.StatsBuckets(stats)
+	for rows.Next() {
+		var kind, bucket string
+		var hits int64
+		if err := rows.Scan(&kind, &bucket, &hits); err != nil {
+			return nil, err
+		}
+		if counts, ok := buckets[kind]; ok {
+			counts[bucket] = hits
+		}
+	}
+	return stats, rows.Err()
+}
+
+// deleteStats removes the statistics of token inside tx; an empty token
+// sweeps rows whose link is gone, e.g. hits flushed by another replica after
+// the delete.
+func deleteStats(ctx context.Context, tx *sql.Tx, token string) error {
+	where, args := "WHERE token NOT IN (SELECT token FROM urls)", []any{}
+	if token != "" {
+		where, args = "WHERE token = $1", []any{token}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM link_stats "+where, args...); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "DELETE FROM link_hit_buckets "+where, args...)
+	return err
+}
@@ -0,0 +1,270 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidBackup is returned for a file that is not an intact shortener
+// database.
+var ErrInvalidBackup = errors.New("not a valid shortener database")
+
+// ErrDatabaseInUse is returned by Restore while another process may still
+// have the database open.
+var ErrDatabaseInUse = errors.New("database is in use")
+
+// Backup writes a consistent snapshot of the database at dsn to dst,
+// replacing dst only once the snapshot is complete. It is safe to run while
+// the server is using the database.
+func Backup(ctx context.Context, dsn, dst string, opts ...Option) error {
+	tuning, err := newTuning(opts)
+	if err != nil {
+		return err
+	}
+	db, err := openSnapshotter(dsn, tuning)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return vacuumInto(ctx, db, dst)
+}
+
+// Snapshot streams a consistent copy of the database to w.
+func (r *Repository) Snapshot(ctx context.Context, w io.Writer) error {
+	db := r.writer
+	if !isMemory(r.dsn) {
+		// A connection of its own keeps the single writer free for uploads
+		// while the copy runs.
+		snapshotter, err := openSnapshotter(r.dsn, r.tuning)
+		if err != nil {
+			return err
+		}
+		defer snapshotter.Close()
+		db = snapshotter
+	}
+
+	dir, err := os.MkdirTemp("", "shortener-snapshot-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "shortener.db")
+	if err := vacuumInto(ctx, db, path); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// openSnapshotter opens a connection for VACUUM INTO, which the read pool's
+// query_only pragma forbids.
+func openSnapshotter(dsn string, tuning Tuning) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", withPragmas(dsn, tuning))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+func vacuumInto(ctx context.Context, db *sql.DB, dst string) error {
+	// VACUUM INTO needs a missing or empty file; a temporary one next to dst
+	// makes the final rename atomic.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", tmp.Name()); err != nil {
+		return err
+	}
+	if _, err := Verify(ctx, tmp.Name()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Verify checks that the database file at path passes an integrity check
+// and has a schema this binary can use.
+func Verify(ctx context.Context, path string) (SchemaStatus, error) {
+	if _, err := os.Stat(path); err != nil {
+		return SchemaStatus{}, err
+	}
+
+	db, err := openReader(path, DefaultTuning())
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return SchemaStatus{}, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return SchemaStatus{}, err
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return SchemaStatus{}, err
+	}
+	if len(problems) > 0 {
+		return SchemaStatus{}, fmt.Errorf("%w: integrity check: %s", ErrInvalidBackup, strings.Join(problems, "; "))
+	}
+
+	var urls int
+	err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'urls'").Scan(&urls)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	if urls == 0 {
+		return SchemaStatus{}, fmt.Errorf("%w: no urls table", ErrInvalidBackup)
+	}
+
+	status, err := schemaStatus(ctx, db)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	if status.TooNew() {
+		return status, fmt.Errorf("%w: version %d, binary supports up to %d", ErrSchemaTooNew, status.Version, status.Latest)
+	}
+	return status, nil
+}
+
+// Restore replaces the database at dsn with the backup at src after
+// verifying it. The current database, if any, is first saved next to it
+// with a .pre-restore suffix. The server must not be running: it would keep
+// using the replaced file, so Restore refuses with ErrDatabaseInUse while
+// the database looks open unless force is set.
+func Restore(ctx context.Context, src, dsn string, force bool, opts ...Option) (previous string, status SchemaStatus, err error) {
+	status, err = Verify(ctx, src)
+	if err != nil {
+		return "", status, err
+	}
+
+	target := dbFile(dsn)
+	if !force {
+		if err := checkNotInUse(ctx, dsn, target); err != nil {
+			return "", status, err
+		}
+	}
+	staged, err := stageCopy(src, target)
+	if err != nil {
+		return "", status, err
+	}
+	defer os.Remove(staged)
+	if _, err := Verify(ctx, staged); err != nil {
+		return "", status, err
+	}
+
+	if _, err := os.Stat(target); err == nil {
+		previous = target + ".pre-restore"
+		if err := Backup(ctx, dsn, previous, opts...); err != nil {
+			return "", status, fmt.Errorf("save current database: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", status, err
+	}
+
+	// A leftover write-ahead log would be replayed into the restored file.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(target + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return previous, status, err
+		}
+	}
+	return previous, status, os.Rename(staged, target)
+}
+
+// checkNotInUse looks for signs of another process using the database at
+// target: the shared-memory file of an open WAL database, or a lock that
+// keeps an exclusive transaction from starting right away.
+func checkNotInUse(ctx context.Context, dsn, target string) error {
+	if _, err := os.Stat(target + "-shm"); err == nil {
+		return fmt.Errorf("%w: %s exists", ErrDatabaseInUse, target+"-shm")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	tuning := DefaultTuning()
+	tuning.BusyTimeout = 0
+	db, err := openSnapshotter(dsn, tuning)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseInUse, err)
+	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK")
+	return err
+}
+
+// stageCopy copies src next to target so it can be renamed into place.
+func stageCopy(src, target string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".restore-")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
+
+// dbFile returns the file a DSN such as "file:/data/x.db?_pragma=..." names.
+func dbFile(dsn string) string {
+	dsn = strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		dsn = dsn[:i]
+	}
+	return dsn
+}
@@ -0,0 +1,206 @@
+package sqlite_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"transfer-shortener/adapter/sqlite"
+	"transfer-shortener/domain/repository"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortener.db")
+	backupPath := filepath.Join(dir, "backup.db")
+
+	repo, err := sqlite.NewRepository(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, newShortURL("before")); err != nil {
+		t.Fatal(err)
+	}
+	// The server keeps running while the backup is taken.
+	if err := sqlite.Backup(ctx, dbPath, backupPath); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := repo.Save(ctx, newShortURL("after")); err != nil {
+		t.Fatal(err)
+	}
+	repo.Close()
+
+	previous, status, err := sqlite.Restore(ctx, backupPath, dbPath, false)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if status.Version != status.Latest {
+		t.Errorf("expected backup at schema version %d, got %d", status.Latest, status.Version)
+	}
+	if previous != dbPath+".pre-restore" {
+		t.Errorf("unexpected previous database path %q", previous)
+	}
+
+	restored := newTestRepository(t, dbPath)
+	if _, err := restored.FindByToken(ctx, "before"); err != nil {
+		t.Errorf("expected the backed up link, got %v", err)
+	}
+	if _, err := restored.FindByToken(ctx, "after"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected the later link to be gone, got %v", err)
+	}
+
+	saved := newTestRepository(t, previous)
+	if _, err := saved.FindByToken(ctx, "after"); err != nil {
+		t.Errorf("expected the replaced database to keep the later link, got %v", err)
+	}
+}
+
+func TestRepository_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, filepath.Join(t.TempDir(), "shortener.db"))
+	if err := repo.Save(ctx, newShortURL("abcd")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := repo.Snapshot(ctx, &buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	snapshotPath := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := os.WriteFile(snapshotPath, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlite.Verify(ctx, snapshotPath); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, err := newTestRepository(t, snapshotPath).FindByToken(ctx, "abcd"); err != nil {
+		t.Errorf("expected the link in the snapshot, got %v", err)
+	}
+}
+
+func TestRestore_RejectsInvalidBackups(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortener.db")
+	repo := newTestRepository(t, dbPath)
+	if err := repo.Save(ctx, newShortURL("keep")); err != nil {
+		t.Fatal(err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, bytes.Repeat([]byte("not a database "), 512), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := filepath.Join(dir, "newer.db")
+	if err := sqlite.Backup(ctx, dbPath, newer); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'from_the_future', 0)")
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want error
+	}{
+		{"corrupt file", garbage, sqlite.ErrInvalidBackup},
+		{"newer schema", newer, sqlite.ErrSchemaTooNew},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := sqlite.Restore(ctx, tt.src, dbPath, false); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+			if _, err := repo.FindByToken(ctx, "keep"); err != nil {
+				t.Errorf("expected the current database to be untouched, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRestore_RefusesDatabaseInUse(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortener.db")
+	backupPath := filepath.Join(dir, "backup.db")
+
+	repo := newTestRepository(t, dbPath)
+	if err := repo.Save(ctx, newShortURL("keep")); err != nil {
+		t.Fatal(err)
+	}
+	if err := sqlite.Backup(ctx, dbPath, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// The repository still has the database open, as a running server would.
+	if _, _, err := sqlite.Restore(ctx, backupPath, dbPath, false); !errors.Is(err, sqlite.ErrDatabaseInUse) {
+		t.Fatalf("expected %v, got %v", sqlite.ErrDatabaseInUse, err)
+	}
+	if _, err := os.Stat(dbPath + ".pre-restore"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no pre-restore copy, got %v", err)
+	}
+	if _, err := repo.FindByToken(ctx, "keep"); err != nil {
+		t.Errorf("expected the current database to be untouched, got %v", err)
+	}
+}
+
+func TestRestore_RefusesLockedDatabase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "shortener.db")
+	backupPath := filepath.Join(dir, "backup.db")
+
+	tuning := sqlite.DefaultTuning()
+	tuning.JournalMode = "DELETE"
+	repo, err := sqlite.NewRepository(dbPath, sqlite.WithTuning(tuning))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, newShortURL("keep")); err != nil {
+		t.Fatal(err)
+	}
+	repo.Close()
+	if err := sqlite.Backup(ctx, dbPath, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without WAL there is no -shm file; the open transaction gives it away.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := sqlite.Restore(ctx, backupPath, dbPath, false); !errors.Is(err, sqlite.ErrDatabaseInUse) {
+		t.Fatalf("expected %v, got %v", sqlite.ErrDatabaseInUse, err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "ROLLBACK"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sqlite.Restore(ctx, backupPath, dbPath, false); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+}
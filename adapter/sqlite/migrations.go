@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"transfer-shortener/domain/repository"
+)
+
+var ErrSchemaTooNew = repository.ErrSchemaTooNew
+
+// Migration is one step of the schema history.
+type Migration struct {
+	Version int
+	Name    string
+	up      func(tx *sql.Tx) error
+}
+
+// AppliedMigration is a row of schema_migrations. Its Name comes from the
+// database, so it is known even for versions this binary does not have.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	AppliedAt time.Time
+}
+
+type SchemaStatus struct {
+	// Version is the highest applied migration, 0 for a database that has
+	// never been versioned.
+	Version int
+	// Latest is the highest migration this binary knows.
+	Latest  int
+	Applied []AppliedMigration
+	Pending []Migration
+}
+
+// TooNew reports whether the database was migrated by a newer binary.
+func (s SchemaStatus) TooNew() bool {
+	return s.Version > s.Latest
+}
+
+// migrations is append-only: never renumber, edit or remove a shipped step.
+// Steps 1-7 predate versioning, so they tolerate databases where an older
+// binary already made their change.
+var migrations = []Migration{
+	{Version: 1, Name: "create_urls", up: execSQL(`
+		CREATE TABLE IF NOT EXISTS urls (
+			token TEXT PRIMARY KEY,
+			full_url TEXT NOT NULL,
+			created_at INTEGER NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_created_at ON urls(created_at);
+	`)},
+	{Version: 2, Name: "add_expires_at", up: func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "urls", "expires_at", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		_, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_expires_at ON urls(expires_at)")
+		return err
+	}},
+	{Version: 3, Name: "add_delete_token", up: addColumn("urls", "delete_token", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 4, Name: "create_link_stats", up: execSQL(`
+		CREATE TABLE IF NOT EXISTS link_stats (
+			token TEXT PRIMARY KEY,
+			hits INTEGER NOT NULL DEFAULT 0,
+			last_access INTEGER NOT NULL DEFAULT 0
+		);
+		CREATE TABLE IF NOT EXISTS link_hit_buckets (
+			token TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			bucket TEXT NOT NULL,
+			hits INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (token, kind, bucket)
+		);
+	`)},
+	{Version: 5, Name: "add_download_limits", up: func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "urls", "max_downloads", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		return addColumnIfMissing(tx, "urls", "downloads", "INTEGER NOT NULL DEFAULT 0")
+	}},
+	{Version: 6, Name: "add_password_hash", up: addColumn("urls", "password_hash", "TEXT NOT NULL DEFAULT ''")},
+	{Version: 7, Name: "add_burn_after_reading", up: addColumn("urls", "burn_after_reading", "INTEGER NOT NULL DEFAULT 0")},
+}
+
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+func addColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, table, column, definition)
+	}
+}
+
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+func latestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Status reports the schema version of the database at dsn without
+// changing it.
+func Status(ctx context.Context, dsn string, opts ...Option) (SchemaStatus, error) {
+	tuning, err := newTuning(opts)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	db, err := openReader(dsn, tuning)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+	defer db.Close()
+	return schemaStatus(ctx, db)
+}
+
+// Migrate brings the database at dsn up to date and returns the migrations
+// it applied. Pass the server's tuning: the journal mode it sets is stored
+// in the database file.
+func Migrate(ctx context.Context, dsn string, opts ...Option) ([]Migration, error) {
+	tuning, err := newTuning(opts)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openWriter(dsn, tuning)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return migrate(ctx, db)
+}
+
+func schemaStatus(ctx context.Context, db *sql.DB) (SchemaStatus, error) {
+	status := SchemaStatus{Latest: latestVersion()}
+
+	var exists int
+	err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	).Scan(&exists)
+	if err != nil {
+		return SchemaStatus{}, err
+	}
+
+	if exists > 0 {
+		rows, err := db.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+		if err != nil {
+			return SchemaStatus{}, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var applied AppliedMigration
+			var appliedAt int64
+			if err := rows.Scan(&applied.Version, &applied.Name, &appliedAt); err != nil {
+				return SchemaStatus{}, err
+			}
+			applied.AppliedAt = time.Unix(appliedAt, 0)
+			status.Applied = append(status.Applied, applied)
+			status.Version = max(status.Version, applied.Version)
+		}
+		if err := rows.Err(); err != nil {
+			return SchemaStatus{}, err
+		}
+	}
+
+	for _, m := range migrations {
+		if m.Version > status.Version {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+	return status, nil
+}
+
+// migrate applies each pending migration in its own transaction together
+// with its schema_migrations row, so an interrupted upgrade resumes at the
+// first step that did not commit.
+func migrate(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := schemaStatus(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if status.TooNew() {
+		return nil, fmt.Errorf("%w: version %d, binary supports up to %d", ErrSchemaTooNew, status.Version, status.Latest)
+	}
+
+	var applied []Migration
+	for _, m := range status.Pending {
+		if err := applyMigration(ctx, db, m); err != nil {
+			return applied, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now().Unix(),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"transfer-shortener/adapter/sqlite"
+)
+
+func TestMigrate_AdoptsUnversionedDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortener.db")
+
+	// Schema as left behind by a binary from before versioned migrations.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE urls (token TEXT PRIMARY KEY, full_url TEXT NOT NULL, created_at INTEGER NOT NULL);
+		ALTER TABLE urls ADD COLUMN expires_at INTEGER NOT NULL DEFAULT 0;
+		INSERT INTO urls (token, full_url, created_at, expires_at) VALUES ('abcd', 'https://example.com/x/file.txt', 1700000000, 0);
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := sqlite.Status(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Version != 0 || len(status.Pending) != status.Latest {
+		t.Errorf("expected every migration pending, got version %d with %d pending", status.Version, len(status.Pending))
+	}
+
+	repo, err := sqlite.NewRepository(dbPath)
+	if err != nil {
+		t.Fatalf("NewRepository: %v", err)
+	}
+	shortURL, err := repo.FindByToken(context.Background(), "abcd")
+	repo.Close()
+	if err != nil {
+		t.Fatalf("FindByToken: %v", err)
+	}
+	if shortURL.FullURL != "https://example.com/x/file.txt" {
+		t.Errorf("unexpected full URL %q", shortURL.FullURL)
+	}
+
+	status, err = sqlite.Status(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Version != status.Latest || len(status.Pending) != 0 || len(status.Applied) != status.Latest {
+		t.Errorf("expected an up-to-date schema, got %+v", status)
+	}
+}
+
+func TestMigrate_IsIdempotent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortener.db")
+
+	applied, err := sqlite.Migrate(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("expected migrations on a new database")
+	}
+
+	applied, err = sqlite.Migrate(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected nothing to apply, got %d migrations", len(applied))
+	}
+}
+
+func TestMigrate_KeepsConfiguredJournalMode(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortener.db")
+	tuning := sqlite.DefaultTuning()
+	tuning.JournalMode = "DELETE"
+
+	if _, err := sqlite.Migrate(context.Background(), dbPath, sqlite.WithTuning(tuning)); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "delete" {
+		t.Errorf("expected journal mode delete, got %q", mode)
+	}
+}
+
+func TestNewRepository_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortener.db")
+	if _, err := sqlite.Migrate(context.Background(), dbPath); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'from_the_future', 0)")
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlite.NewRepository(dbPath); !errors.Is(err, sqlite.ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+
+	status, err := sqlite.Status(context.Background(), dbPath)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if !status.TooNew() {
+		t.Errorf("expected status to report a newer schema, got %+v", status)
+	}
+}
@@ -6,77 +6,201 @@ import (
 	"errors"
 	"time"
 
+	"transfer-shortener/adapter/sqlrow"
 	"transfer-shortener/domain/entity"
 	"transfer-shortener/domain/repository"
 
-	_ "modernc.org/sqlite"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
-var ErrNotFound = errors.New("short URL not found")
+var ErrNotFound = repository.ErrNotFound
 
 type Repository struct {
-	db *sql.DB
+	writer *sql.DB
+	reader *sql.DB
+	dsn    string
+	tuning Tuning
 }
 
-var _ repository.URLRepository = (*Repository)(nil)
+var (
+	_ repository.URLRepository = (*Repository)(nil)
+	_ repository.Snapshotter   = (*Repository)(nil)
+)
 
-func NewRepository(dbPath string) (*Repository, error) {
-	db, err := sql.Open("sqlite", dbPath)
+func NewRepository(dbPath string, opts ...Option) (*Repository, error) {
+	tuning, err := newTuning(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := migrate(db); err != nil {
-		db.Close()
+	writer, err := openWriter(dbPath, tuning)
+	if err != nil {
 		return nil, err
 	}
 
-	return &Repository{db: db}, nil
-}
+	if _, err := migrate(context.Background(), writer); err != nil {
+		writer.Close()
+		return nil, err
+	}
 
-func (r *Repository) Close() error {
-	return r.db.Close()
+	reader := writer
+	if !isMemory(dbPath) {
+		reader, err = openReader(dbPath, tuning)
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+	}
+
+	return &Repository{writer: writer, reader: reader, dsn: dbPath, tuning: tuning}, nil
 }
 
-func migrate(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS urls (
-			token TEXT PRIMARY KEY,
-			full_url TEXT NOT NULL,
-			created_at INTEGER NOT NULL
-		);
-		CREATE INDEX IF NOT EXISTS idx_created_at ON urls(created_at);
-	`)
+func (r *Repository) Close() error {
+	err := r.writer.Close()
+	if r.reader != r.writer {
+		err = errors.Join(err, r.reader.Close())
+	}
 	return err
 }
 
+const insertURL = "INSERT INTO urls (" + sqlrow.URLColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
 func (r *Repository) Save(ctx context.Context, shortURL *entity.ShortURL) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO urls (token, full_url, created_at) VALUES (?, ?, ?)",
-		shortURL.Token, shortURL.FullURL, shortURL.CreatedAt.Unix(),
-	)
+	_, err := r.writer.ExecContext(ctx, insertURL, sqlrow.InsertArgs(shortURL)...)
+	if isUniqueViolation(err) {
+		return repository.ErrDuplicateToken
+	}
 	return err
 }
 
+func (r *Repository) SaveAll(ctx context.Context, shortURLs []*entity.ShortURL) error {
+	tx, err := r.writer.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, insertURL)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, shortURL := range shortURLs {
+		if _, err := stmt.ExecContext(ctx, sqlrow.InsertArgs(shortURL)...); err != nil {
+			if isUniqueViolation(err) {
+				return repository.ErrDuplicateToken
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
 func (r *Repository) FindByToken(ctx context.Context, token string) (*entity.ShortURL, error) {
-	var fullURL string
-	var createdAt int64
+	shortURL, err := sqlrow.ScanURL(r.reader.QueryRowContext(ctx,
+		"SELECT "+sqlrow.URLColumns+" FROM urls WHERE token = ?",
+		token,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return shortURL, err
+}
+
+// ConsumeDownload increments the counter only while it is below the limit,
+// so concurrent downloads can never overshoot it.
+func (r *Repository) ConsumeDownload(ctx context.Context, token string) error {
+	result, err := r.writer.ExecContext(ctx,
+		"UPDATE urls SET downloads = downloads + 1 WHERE token = ? AND max_downloads > 0 AND downloads < max_downloads",
+		token,
+	)
+	if err != nil {
+		return err
+	}
 
-	err := r.db.QueryRowContext(ctx,
-		"SELECT full_url, created_at FROM urls WHERE token = ?",
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrDownloadLimitReached
+	}
+	return nil
+}
+
+func (r *Repository) ReleaseDownload(ctx context.Context, token string) error {
+	_, err := r.writer.ExecContext(ctx,
+		"UPDATE urls SET downloads = downloads - 1 WHERE token = ? AND downloads > 0",
 		token,
-	).Scan(&fullURL, &createdAt)
+	)
+	return err
+}
 
+func (r *Repository) Delete(ctx context.Context, token string) error {
+	tx, err := r.writer.BeginTx(ctx, nil)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrNotFound
-		}
-		return nil, err
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM urls WHERE token = ?", token)
+	if err != nil {
+		return err
 	}
 
-	return &entity.ShortURL{
-		Token:     token,
-		FullURL:   fullURL,
-		CreatedAt: time.Unix(createdAt, 0),
-	}, nil
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	if err := deleteStats(ctx, tx, token); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteExpired also drops statistics left behind by removed links,
+// including hits recorded after their link was deleted.
+func (r *Repository) DeleteExpired(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+	query := "DELETE FROM urls WHERE (expires_at > 0 AND expires_at <= ?) OR (max_downloads > 0 AND downloads >= max_downloads)"
+	args := []any{now.Unix()}
+	if !createdBefore.IsZero() {
+		query += " OR created_at < ?"
+		args = append(args, createdBefore.Unix())
+	}
+
+	tx, err := r.writer.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := deleteStats(ctx, tx, ""); err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
 }
@@ -0,0 +1,134 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"transfer-shortener/adapter/sqlite"
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/domain/repository/repositorytest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.URLRepository {
+		return newTestRepository(t, filepath.Join(t.TempDir(), "shortener.db"))
+	})
+}
+
+func newTestRepository(tb testing.TB, dbPath string, opts ...sqlite.Option) *sqlite.Repository {
+	repo, err := sqlite.NewRepository(dbPath, opts...)
+	if err != nil {
+		tb.Fatalf("open repository: %v", err)
+	}
+	tb.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestNewRepository_EnablesWAL(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "shortener.db")
+	newTestRepository(t, dbPath)
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatal(err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected journal mode wal, got %q", mode)
+	}
+}
+
+func TestNewRepository_RejectsInvalidTuning(t *testing.T) {
+	tuning := sqlite.DefaultTuning()
+	tuning.Synchronous = "NORMAL); DROP TABLE urls; --"
+
+	_, err := sqlite.NewRepository(filepath.Join(t.TempDir(), "shortener.db"), sqlite.WithTuning(tuning))
+	if err == nil {
+		t.Fatal("expected an error for an unknown synchronous mode")
+	}
+}
+
+func TestRepository_ConcurrentSavesAndReads(t *testing.T) {
+	repo := newTestRepository(t, filepath.Join(t.TempDir(), "shortener.db"))
+	ctx := context.Background()
+
+	const workers, perWorker = 16, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWorker {
+				token := fmt.Sprintf("w%d-%d", w, i)
+				if err := repo.Save(ctx, newShortURL(token)); err != nil {
+					errs <- fmt.Errorf("save %s: %w", token, err)
+					continue
+				}
+				if _, err := repo.FindByToken(ctx, token); err != nil {
+					errs <- fmt.Errorf("find %s: %w", token, err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+// BenchmarkRepository_ResolveAndSave mixes one save into every ten resolves,
+// roughly an upload burst against steady link traffic.
+func BenchmarkRepository_ResolveAndSave(b *testing.B) {
+	repo := newTestRepository(b, filepath.Join(b.TempDir(), "shortener.db"))
+	ctx := context.Background()
+
+	const seeded = 1000
+	for i := range seeded {
+		if err := repo.Save(ctx, newShortURL(fmt.Sprintf("seed%d", i))); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var next atomic.Int64
+	b.SetParallelism(4)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := next.Add(1)
+			if n%10 == 0 {
+				if err := repo.Save(ctx, newShortURL(fmt.Sprintf("bench%d", n))); err != nil {
+					b.Error(err)
+					return
+				}
+				continue
+			}
+			if _, err := repo.FindByToken(ctx, fmt.Sprintf("seed%d", n%seeded)); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func newShortURL(token string) *entity.ShortURL {
+	return &entity.ShortURL{
+		Token:     token,
+		FullURL:   "https://example.com/abc/" + token + ".txt",
+		CreatedAt: time.Now(),
+	}
+}
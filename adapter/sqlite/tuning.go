@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tuning controls how the database is opened. Writes go through a single
+// connection so they queue in Go instead of failing with SQLITE_BUSY, while
+// reads use a separate pool that WAL mode lets run alongside the writer.
+type Tuning struct {
+	// JournalMode is persisted in the database file; WAL lets readers and
+	// the writer proceed concurrently.
+	JournalMode string
+	// Synchronous NORMAL is durable in WAL mode except for the last commits
+	// before a power loss.
+	Synchronous string
+	// BusyTimeout is how long a connection waits for a lock held by another
+	// process, such as the migrate or backup commands.
+	BusyTimeout  time.Duration
+	ForeignKeys  bool
+	MaxReadConns int
+}
+
+func DefaultTuning() Tuning {
+	return Tuning{
+		JournalMode:  "WAL",
+		Synchronous:  "NORMAL",
+		BusyTimeout:  5 * time.Second,
+		ForeignKeys:  true,
+		MaxReadConns: max(4, runtime.NumCPU()),
+	}
+}
+
+var (
+	journalModes     = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	synchronousModes = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+func (t Tuning) validate() error {
+	if !slices.Contains(journalModes, strings.ToUpper(t.JournalMode)) {
+		return fmt.Errorf("unknown journal mode %q", t.JournalMode)
+	}
+	if !slices.Contains(synchronousModes, strings.ToUpper(t.Synchronous)) {
+		return fmt.Errorf("unknown synchronous mode %q", t.Synchronous)
+	}
+	if t.BusyTimeout < 0 {
+		return fmt.Errorf("negative busy timeout %s", t.BusyTimeout)
+	}
+	if t.MaxReadConns < 1 {
+		return fmt.Errorf("max read connections must be at least 1, got %d", t.MaxReadConns)
+	}
+	return nil
+}
+
+// Option configures a Repository.
+type Option func(*Tuning)
+
+func WithTuning(tuning Tuning) Option {
+	return func(t *Tuning) {
+		*t = tuning
+	}
+}
+
+// newTuning applies opts to the defaults and validates the result.
+func newTuning(opts []Option) (Tuning, error) {
+	tuning := DefaultTuning()
+	for _, opt := range opts {
+		opt(&tuning)
+	}
+	return tuning, tuning.validate()
+}
+
+// openWriter opens the single connection all writes and migrations use.
+func openWriter(dsn string, tuning Tuning) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", withPragmas(dsn, tuning,
+		"journal_mode("+tuning.JournalMode+")",
+		"synchronous("+tuning.Synchronous+")",
+	)+"&_txlock=immediate")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	// Set the journal mode now rather than racing the first reader.
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func openReader(dsn string, tuning Tuning) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", withPragmas(dsn, tuning, "query_only(1)"))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(tuning.MaxReadConns)
+	db.SetMaxIdleConns(tuning.MaxReadConns)
+	return db, nil
+}
+
+// withPragmas appends connection pragmas to dsn, which may already carry
+// query parameters of its own.
+func withPragmas(dsn string, tuning Tuning, pragmas ...string) string {
+	foreignKeys := "0"
+	if tuning.ForeignKeys {
+		foreignKeys = "1"
+	}
+	pragmas = append(pragmas,
+		fmt.Sprintf("busy_timeout(%d)", tuning.BusyTimeout.Milliseconds()),
+		"foreign_keys("+foreignKeys+")",
+	)
+
+	query := url.Values{"_pragma": pragmas}.Encode()
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + query
+	}
+	return dsn + "?" + query
+}
+
+// isMemory reports whether dsn names an in-memory database, which exists
+// per connection and so cannot be shared with a read pool.
+func isMemory(dsn string) bool {
+	return strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
@@ -0,0 +1,81 @@
+// Package sqlrow maps short links and their statistics to the column values
+// the SQL adapters store, so SQLite and PostgreSQL agree on the encoding.
+package sqlrow
+
+import (
+	"time"
+
+	"transfer-shortener/domain/entity"
+)
+
+// URLColumns lists the urls columns in the order of InsertArgs.
+const URLColumns = "token, full_url, created_at, expires_at, delete_token, max_downloads, downloads, password_hash, burn_after_reading"
+
+// InsertArgs returns the values of shortURL for an insert into URLColumns.
+func InsertArgs(shortURL *entity.ShortURL) []any {
+	return []any{
+		shortURL.Token, shortURL.FullURL, shortURL.CreatedAt.Unix(), ToUnix(shortURL.ExpiresAt), shortURL.DeleteToken,
+		shortURL.MaxDownloads, shortURL.Downloads, shortURL.PasswordHash, shortURL.BurnAfterReading,
+	}
+}
+
+// Scanner is a *sql.Row or *sql.Rows.
+type Scanner interface {
+	Scan(dest ...any) error
+}
+
+// ScanURL reads a row selected with URLColumns, passing on the error of an
+// empty result as is.
+func ScanURL(row Scanner) (*entity.ShortURL, error) {
+	var shortURL entity.ShortURL
+	var createdAt, expiresAt int64
+	err := row.Scan(&shortURL.Token, &shortURL.FullURL, &createdAt, &expiresAt, &shortURL.DeleteToken,
+		&shortURL.MaxDownloads, &shortURL.Downloads, &shortURL.PasswordHash, &shortURL.BurnAfterReading)
+	if err != nil {
+		return nil, err
+	}
+	shortURL.CreatedAt = time.Unix(createdAt, 0)
+	shortURL.ExpiresAt = FromUnix(expiresAt)
+	return &shortURL, nil
+}
+
+// ToUnix stores the zero time as 0 so "no expiry" survives a round trip.
+func ToUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func FromUnix(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// Hit bucket kinds stored in link_hit_buckets.
+const (
+	bucketReferrer = "referrer"
+	bucketClient   = "client"
+	bucketDay      = "day"
+)
+
+// HitBuckets returns the (kind, bucket) pairs a hit counts towards.
+func HitBuckets(hit entity.Hit) [][2]string {
+	return [][2]string{
+		{bucketReferrer, hit.Referrer},
+		{bucketClient, hit.Client},
+		{bucketDay, hit.Day()},
+	}
+}
+
+// StatsBuckets returns the maps of stats that link_hit_buckets rows fill in,
+// keyed by kind.
+func StatsBuckets(stats *entity.LinkStats) map[string]map[string]int64 {
+	return map[string]map[string]int64{
+		bucketReferrer: stats.Referrers,
+		bucketClient:   stats.Clients,
+		bucketDay:      stats.Days,
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"transfer-shortener/adapter/sqlite"
+)
+
+// runBackup writes a consistent snapshot of the SQLite database to the path
+// in args. It is safe to run next to a live server.
+func runBackup(ctx context.Context, config Config, args []string) error {
+	if config.DBDriver != "sqlite" {
+		return fmt.Errorf("backup only handles sqlite databases, DB_DRIVER is %q; use pg_dump for postgres", config.DBDriver)
+	}
+	if len(args) != 1 {
+		return errors.New("usage: shortener backup <file>")
+	}
+
+	// Backup verifies the snapshot before moving it into place.
+	if err := sqlite.Backup(ctx, sqliteDSN(config), args[0], sqlite.WithTuning(config.SQLiteTuning)); err != nil {
+		return err
+	}
+	fmt.Printf("backed up to %s\n", args[0])
+	return nil
+}
+
+// runRestore replaces the SQLite database with the backup in args once it has
+// been verified. The server must be stopped first; --force skips the check
+// for a database still in use, e.g. after a crash left its -shm file behind.
+func runRestore(ctx context.Context, config Config, args []string) error {
+	if config.DBDriver != "sqlite" {
+		return fmt.Errorf("restore only handles sqlite databases, DB_DRIVER is %q; use pg_restore for postgres", config.DBDriver)
+	}
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	force := flags.Bool("force", false, "restore even if the database looks in use")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: shortener restore [--force] <file>")
+	}
+	src := flags.Arg(0)
+
+	previous, status, err := sqlite.Restore(ctx, src, sqliteDSN(config), *force, sqlite.WithTuning(config.SQLiteTuning))
+	if errors.Is(err, sqlite.ErrDatabaseInUse) {
+		return fmt.Errorf("%w; stop the server first, or pass --force if it is not running", err)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %s (schema version %d)\n", src, status.Version)
+	if previous != "" {
+		fmt.Printf("previous database saved to %s\n", previous)
+	}
+	if status.Version < status.Latest {
+		fmt.Printf("%d migrations will be applied on the next start\n", len(status.Pending))
+	}
+	return nil
+}
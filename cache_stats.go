@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"transfer-shortener/adapter/cache"
+	httpAdapter "transfer-shortener/adapter/http"
+)
+
+// runCacheStats logs the link cache counters every interval and once more
+// on shutdown.
+func runCacheStats(ctx context.Context, links *cache.Repository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logCacheStats(links)
+			return
+		case <-ticker.C:
+			logCacheStats(links)
+		}
+	}
+}
+
+func logCacheStats(links *cache.Repository) {
+	stats := links.CacheStats()
+	log.Printf("cache: hits=%d negative_hits=%d misses=%d evictions=%d entries=%d",
+		stats.Hits, stats.NegativeHits, stats.Misses, stats.Evictions, stats.Entries)
+}
+
+// cacheStatsSource hands the link cache counters to the admin API.
+type cacheStatsSource struct {
+	links *cache.Repository
+}
+
+func (s cacheStatsSource) CacheStats() httpAdapter.CacheStats {
+	stats := s.links.CacheStats()
+	return httpAdapter.CacheStats{
+		Hits:         stats.Hits,
+		NegativeHits: stats.NegativeHits,
+		Misses:       stats.Misses,
+		Evictions:    stats.Evictions,
+		Entries:      stats.Entries,
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// Client classes a hit's user agent is sorted into.
+const (
+	ClientBrowser = "browser"
+	ClientCLI     = "cli"
+	ClientBot     = "bot"
+	ClientOther   = "other"
+)
+
+// Hit is one resolution of a short link.
+type Hit struct {
+	Token    string
+	At       time.Time
+	Referrer string // referring host, "direct" when there is none
+	Client   string
+}
+
+// Day is the UTC day bucket of the hit, e.g. "2026-01-02".
+func (h Hit) Day() string {
+	return h.At.UTC().Format(time.DateOnly)
+}
+
+// LinkStats aggregates the hits of one link, bucketed by referrer host, client
+// class and UTC day.
+type LinkStats struct {
+	Token      string
+	Hits       int64
+	LastAccess time.Time
+	Referrers  map[string]int64
+	Clients    map[string]int64
+	Days       map[string]int64
+}
@@ -1,37 +1,89 @@
 package entity
 
 import (
+	"crypto/pbkdf2"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
 var (
-	ErrEmptyURL   = errors.New("URL cannot be empty")
-	ErrInvalidURL = errors.New("invalid URL format")
+	ErrEmptyURL      = errors.New("URL cannot be empty")
+	ErrInvalidURL    = errors.New("invalid URL format")
+	ErrInvalidAlias  = errors.New("alias must be 3-64 letters, digits, '-' or '_'")
+	ErrReservedAlias = errors.New("alias is reserved")
 )
 
-const DefaultTokenLength = 4
+const (
+	DefaultTokenLength = 4
+	MaxTokenLength     = 8
+
+	MinAliasLength = 3
+	MaxAliasLength = 64
+)
+
+// reservedAliases are paths the shortener or transfer.sh serve themselves.
+var reservedAliases = map[string]bool{
+	"admin":      true,
+	"api":        true,
+	"css":        true,
+	"download":   true,
+	"favicon":    true,
+	"fonts":      true,
+	"get":        true,
+	"health":     true,
+	"images":     true,
+	"img":        true,
+	"info":       true,
+	"inline":     true,
+	"js":         true,
+	"qr":         true,
+	"raw":        true,
+	"robots":     true,
+	"scan":       true,
+	"static":     true,
+	"tar":        true,
+	"upload":     true,
+	"virustotal": true,
+	"zip":        true,
+}
 
 type ShortURL struct {
 	Token     string
 	FullURL   string
 	CreatedAt time.Time
+	// ExpiresAt is zero when the link never expires.
+	ExpiresAt time.Time
+	// DeleteToken is the transfer.sh deletion secret for the file, if known.
+	DeleteToken string
+	// MaxDownloads limits how often the link may be downloaded; 0 means no
+	// limit. Downloads counts those already served.
+	MaxDownloads int
+	Downloads    int
+	// PasswordHash gates the link behind a password; empty when it is open.
+	PasswordHash string
+	// BurnAfterReading links allow a single download, after which the backend
+	// file is deleted.
+	BurnAfterReading bool
 }
 
 func NewShortURL(fullURL string) (*ShortURL, error) {
-	if fullURL == "" {
-		return nil, ErrEmptyURL
-	}
+	return NewShortURLWithTokenLength(fullURL, DefaultTokenLength)
+}
 
-	parsed, err := url.Parse(fullURL)
-	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
-		return nil, ErrInvalidURL
+func NewShortURLWithTokenLength(fullURL string, tokenLength int) (*ShortURL, error) {
+	if err := validateFullURL(fullURL); err != nil {
+		return nil, err
 	}
 
-	token, err := generateToken(DefaultTokenLength)
+	token, err := generateToken(tokenLength)
 	if err != nil {
 		return nil, err
 	}
@@ -43,10 +95,182 @@ func NewShortURL(fullURL string) (*ShortURL, error) {
 	}, nil
 }
 
+// NewShortURLWithAlias creates a link whose token is the caller-chosen alias.
+func NewShortURLWithAlias(fullURL, alias string) (*ShortURL, error) {
+	if err := validateFullURL(fullURL); err != nil {
+		return nil, err
+	}
+	if err := ValidateAlias(alias); err != nil {
+		return nil, err
+	}
+
+	return &ShortURL{
+		Token:     alias,
+		FullURL:   fullURL,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// ValidateAlias checks a vanity alias against the allowed character set,
+// length bounds and reserved words. Aliases are case-sensitive like tokens,
+// but reserved words are matched case-insensitively.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return ErrInvalidAlias
+	}
+	for _, c := range alias {
+		if !isAliasChar(c) {
+			return ErrInvalidAlias
+		}
+	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return ErrReservedAlias
+	}
+	return nil
+}
+
+func isAliasChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func validateFullURL(fullURL string) error {
+	if fullURL == "" {
+		return ErrEmptyURL
+	}
+
+	parsed, err := url.Parse(fullURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// RegenerateToken replaces the token with a fresh random one, used when the
+// previous token collided with an existing link.
+func (s *ShortURL) RegenerateToken(length int) error {
+	token, err := generateToken(length)
+	if err != nil {
+		return err
+	}
+	s.Token = token
+	return nil
+}
+
 func (s *ShortURL) IsExpired(ttl time.Duration) bool {
 	return time.Since(s.CreatedAt) > ttl
 }
 
+// ExpireAfterDays sets the expiry relative to creation time, mirroring
+// transfer.sh's Max-Days. Non-positive values leave the link without expiry.
+func (s *ShortURL) ExpireAfterDays(days int) {
+	if days <= 0 {
+		s.ExpiresAt = time.Time{}
+		return
+	}
+	s.ExpiresAt = s.CreatedAt.AddDate(0, 0, days)
+}
+
+func (s *ShortURL) HasExpired() bool {
+	return !s.ExpiresAt.IsZero() && time.Now().After(s.ExpiresAt)
+}
+
+// MakeOneTime limits the link to a single download that burns the file.
+func (s *ShortURL) MakeOneTime() {
+	s.MaxDownloads = 1
+	s.BurnAfterReading = true
+}
+
+// RemainingDownloads returns how many downloads the limit still allows, or -1
+// for links without a limit.
+func (s *ShortURL) RemainingDownloads() int {
+	if s.MaxDownloads <= 0 {
+		return -1
+	}
+	return max(s.MaxDownloads-s.Downloads, 0)
+}
+
+// DownloadsExhausted reports whether a limited link has been downloaded as
+// often as allowed.
+func (s *ShortURL) DownloadsExhausted() bool {
+	return s.RemainingDownloads() == 0
+}
+
+// DeleteURL returns the transfer.sh delete endpoint for the file, or an empty
+// string when no deletion token was recorded.
+func (s *ShortURL) DeleteURL() string {
+	if s.DeleteToken == "" {
+		return ""
+	}
+	return s.FullURL + "/" + s.DeleteToken
+}
+
+// Passwords are hashed with PBKDF2-HMAC-SHA256 and stored as
+// "pbkdf2-sha256$<iterations>$<salt>$<key>", so the cost can be raised later
+// without invalidating existing links.
+const (
+	passwordScheme     = "pbkdf2-sha256"
+	passwordIterations = 100_000
+	passwordSaltLength = 16
+	passwordKeyLength  = 32
+)
+
+// SetPassword stores a salted hash of password; an empty password opens the
+// link again.
+func (s *ShortURL) SetPassword(password string) error {
+	if password == "" {
+		s.PasswordHash = ""
+		return nil
+	}
+
+	salt := make([]byte, passwordSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLength)
+	if err != nil {
+		return err
+	}
+
+	s.PasswordHash = fmt.Sprintf("%s$%d$%s$%s", passwordScheme, passwordIterations,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+	return nil
+}
+
+func (s *ShortURL) HasPassword() bool {
+	return s.PasswordHash != ""
+}
+
+// CheckPassword reports whether password matches the stored hash. Open links
+// accept any password.
+func (s *ShortURL) CheckPassword(password string) bool {
+	if !s.HasPassword() {
+		return true
+	}
+
+	parts := strings.Split(s.PasswordHash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
 func generateToken(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
@@ -1,6 +1,8 @@
 package entity_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -45,6 +47,69 @@ func TestNewShortURL_RejectsInvalidURL(t *testing.T) {
 	}
 }
 
+func TestNewShortURLWithTokenLength(t *testing.T) {
+	shortURL, err := entity.NewShortURLWithTokenLength("https://example.com/abc12/file.txt", 6)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(shortURL.Token) != 6 {
+		t.Errorf("expected Token length 6, got %d", len(shortURL.Token))
+	}
+}
+
+func TestNewShortURLWithAlias(t *testing.T) {
+	shortURL, err := entity.NewShortURLWithAlias("https://example.com/abc12/notes.md", "release-notes")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if shortURL.Token != "release-notes" {
+		t.Errorf("expected Token release-notes, got %s", shortURL.Token)
+	}
+}
+
+func TestValidateAlias(t *testing.T) {
+	tests := []struct {
+		name     string
+		alias    string
+		expected error
+	}{
+		{"valid alias", "release-notes", nil},
+		{"underscores and digits", "build_2024", nil},
+		{"too short", "ab", entity.ErrInvalidAlias},
+		{"too long", strings.Repeat("a", entity.MaxAliasLength+1), entity.ErrInvalidAlias},
+		{"slash", "a/b/c", entity.ErrInvalidAlias},
+		{"dot", "file.txt", entity.ErrInvalidAlias},
+		{"non-ascii", "résumé", entity.ErrInvalidAlias},
+		{"reserved word", "health", entity.ErrReservedAlias},
+		{"reserved word any case", "Inline", entity.ErrReservedAlias},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := entity.ValidateAlias(tt.alias); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestShortURL_RegenerateToken(t *testing.T) {
+	shortURL, err := entity.NewShortURL("https://example.com/abc12/file.txt")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := shortURL.RegenerateToken(5); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(shortURL.Token) != 5 {
+		t.Errorf("expected Token length 5, got %d", len(shortURL.Token))
+	}
+}
+
 func TestShortURL_IsExpired(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -80,3 +145,136 @@ func TestShortURL_IsExpired(t *testing.T) {
 		})
 	}
 }
+
+func TestShortURL_ExpireAfterDays(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	shortURL := &entity.ShortURL{CreatedAt: createdAt}
+
+	shortURL.ExpireAfterDays(3)
+
+	expected := createdAt.AddDate(0, 0, 3)
+	if !shortURL.ExpiresAt.Equal(expected) {
+		t.Errorf("expected ExpiresAt %v, got %v", expected, shortURL.ExpiresAt)
+	}
+
+	shortURL.ExpireAfterDays(0)
+
+	if !shortURL.ExpiresAt.IsZero() {
+		t.Errorf("expected no expiry for 0 days, got %v", shortURL.ExpiresAt)
+	}
+}
+
+func TestShortURL_HasExpired(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		expected  bool
+	}{
+		{
+			name:      "never expires without expiry",
+			expiresAt: time.Time{},
+			expected:  false,
+		},
+		{
+			name:      "not expired before expiry",
+			expiresAt: time.Now().Add(time.Hour),
+			expected:  false,
+		},
+		{
+			name:      "expired after expiry",
+			expiresAt: time.Now().Add(-time.Hour),
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortURL := &entity.ShortURL{
+				Token:     "test",
+				FullURL:   "https://example.com/file.txt",
+				CreatedAt: time.Now().Add(-24 * time.Hour),
+				ExpiresAt: tt.expiresAt,
+			}
+
+			if shortURL.HasExpired() != tt.expected {
+				t.Errorf("expected HasExpired=%v, got %v", tt.expected, !tt.expected)
+			}
+		})
+	}
+}
+
+func TestShortURL_DeleteURL(t *testing.T) {
+	shortURL := &entity.ShortURL{FullURL: "https://example.com/abc12/file.txt"}
+
+	if got := shortURL.DeleteURL(); got != "" {
+		t.Errorf("expected empty DeleteURL without token, got %q", got)
+	}
+
+	shortURL.DeleteToken = "s3cr3t"
+
+	expected := "https://example.com/abc12/file.txt/s3cr3t"
+	if got := shortURL.DeleteURL(); got != expected {
+		t.Errorf("expected DeleteURL %q, got %q", expected, got)
+	}
+}
+
+func TestHit_DayIsUTC(t *testing.T) {
+	hit := entity.Hit{At: time.Date(2026, 1, 2, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))}
+
+	if got := hit.Day(); got != "2026-01-03" {
+		t.Errorf("expected UTC day 2026-01-03, got %s", got)
+	}
+}
+
+func TestShortURL_RemainingDownloads(t *testing.T) {
+	tests := []struct {
+		name              string
+		maxDownloads      int
+		downloads         int
+		expectedRemaining int
+		expectedExhausted bool
+	}{
+		{"unlimited", 0, 5, -1, false},
+		{"downloads left", 3, 1, 2, false},
+		{"used up", 1, 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &entity.ShortURL{MaxDownloads: tt.maxDownloads, Downloads: tt.downloads}
+
+			if got := s.RemainingDownloads(); got != tt.expectedRemaining {
+				t.Errorf("expected %d remaining, got %d", tt.expectedRemaining, got)
+			}
+			if got := s.DownloadsExhausted(); got != tt.expectedExhausted {
+				t.Errorf("expected exhausted %v, got %v", tt.expectedExhausted, got)
+			}
+		})
+	}
+}
+
+func TestShortURL_Password(t *testing.T) {
+	s := &entity.ShortURL{}
+	if !s.CheckPassword("") {
+		t.Error("expected open link to accept any password")
+	}
+
+	if err := s.SetPassword("s3cr3t"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.HasPassword() || strings.Contains(s.PasswordHash, "s3cr3t") {
+		t.Fatalf("expected a hash, got %q", s.PasswordHash)
+	}
+	if !s.CheckPassword("s3cr3t") {
+		t.Error("expected correct password to match")
+	}
+	if s.CheckPassword("wrong") || s.CheckPassword("") {
+		t.Error("expected wrong password to be rejected")
+	}
+
+	other := &entity.ShortURL{}
+	other.SetPassword("s3cr3t")
+	if other.PasswordHash == s.PasswordHash {
+		t.Error("expected a fresh salt per link")
+	}
+}
@@ -0,0 +1,312 @@
+// Package repositorytest is a conformance suite every URLRepository
+// implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+)
+
+// Run exercises the URLRepository contract. newRepo must return an empty
+// repository for each call.
+func Run(t *testing.T, newRepo func(t *testing.T) repository.URLRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repository.URLRepository)
+	}{
+		{"SaveAndFind", testSaveAndFind},
+		{"FindMissing", testFindMissing},
+		{"SaveDuplicate", testSaveDuplicate},
+		{"SaveAllIsAtomic", testSaveAllIsAtomic},
+		{"Delete", testDelete},
+		{"DeleteExpired", testDeleteExpired},
+		{"ConsumeDownload", testConsumeDownload},
+		{"RecordHitsAndStats", testRecordHitsAndStats},
+		{"DeleteDropsStats", testDeleteDropsStats},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+// now is truncated to seconds, the precision repositories store.
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+func save(t *testing.T, repo repository.URLRepository, shortURL *entity.ShortURL) {
+	t.Helper()
+	if err := repo.Save(context.Background(), shortURL); err != nil {
+		t.Fatalf("save %s: %v", shortURL.Token, err)
+	}
+}
+
+func testSaveAndFind(t *testing.T, repo repository.URLRepository) {
+	created := now()
+	want := &entity.ShortURL{
+		Token:        "abc1",
+		FullURL:      "https://transfer.sixtyfive.me/abc12/file.txt",
+		CreatedAt:    created,
+		ExpiresAt:    created.Add(24 * time.Hour),
+		DeleteToken:  "s3cr3t",
+		MaxDownloads: 3,
+	}
+	if err := want.SetPassword("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	save(t, repo, want)
+
+	plain := &entity.ShortURL{Token: "abc2", FullURL: "https://transfer.sixtyfive.me/abc13/once.txt", CreatedAt: created}
+	plain.MakeOneTime()
+	save(t, repo, plain)
+
+	got, err := repo.FindByToken(context.Background(), "abc1")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.Token != want.Token || got.FullURL != want.FullURL || got.DeleteToken != want.DeleteToken {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || !got.ExpiresAt.Equal(want.ExpiresAt) {
+		t.Errorf("expected times %v/%v, got %v/%v", want.CreatedAt, want.ExpiresAt, got.CreatedAt, got.ExpiresAt)
+	}
+	if got.MaxDownloads != 3 || got.Downloads != 0 || got.BurnAfterReading {
+		t.Errorf("expected 3 downloads allowed, got %+v", got)
+	}
+	if !got.CheckPassword("hunter2") {
+		t.Error("expected password hash to survive a round trip")
+	}
+
+	got, err = repo.FindByToken(context.Background(), "abc2")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if !got.ExpiresAt.IsZero() || got.HasPassword() {
+		t.Errorf("expected no expiry and no password, got %+v", got)
+	}
+	if !got.BurnAfterReading || got.MaxDownloads != 1 {
+		t.Errorf("expected one-time link, got %+v", got)
+	}
+}
+
+func testFindMissing(t *testing.T, repo repository.URLRepository) {
+	if _, err := repo.FindByToken(context.Background(), "nope"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func testSaveDuplicate(t *testing.T, repo repository.URLRepository) {
+	save(t, repo, &entity.ShortURL{Token: "abc1", FullURL: "https://a", CreatedAt: now()})
+
+	err := repo.Save(context.Background(), &entity.ShortURL{Token: "abc1", FullURL: "https://b", CreatedAt: now()})
+
+	if !errors.Is(err, repository.ErrDuplicateToken) {
+		t.Errorf("expected ErrDuplicateToken, got %v", err)
+	}
+}
+
+func testSaveAllIsAtomic(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	save(t, repo, &entity.ShortURL{Token: "taken", FullURL: "https://a", CreatedAt: now()})
+
+	err := repo.SaveAll(ctx, []*entity.ShortURL{
+		{Token: "new1", FullURL: "https://b", CreatedAt: now()},
+		{Token: "taken", FullURL: "https://c", CreatedAt: now()},
+	})
+	if !errors.Is(err, repository.ErrDuplicateToken) {
+		t.Fatalf("expected ErrDuplicateToken, got %v", err)
+	}
+	if _, err := repo.FindByToken(ctx, "new1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected no link stored from a failed batch, got %v", err)
+	}
+
+	err = repo.SaveAll(ctx, []*entity.ShortURL{
+		{Token: "new1", FullURL: "https://b", CreatedAt: now()},
+		{Token: "new2", FullURL: "https://c", CreatedAt: now()},
+	})
+	if err != nil {
+		t.Fatalf("save all: %v", err)
+	}
+	for _, token := range []string{"new1", "new2"} {
+		if _, err := repo.FindByToken(ctx, token); err != nil {
+			t.Errorf("expected %s to be stored, got %v", token, err)
+		}
+	}
+}
+
+func testDelete(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	save(t, repo, &entity.ShortURL{Token: "abc1", FullURL: "https://a", CreatedAt: now()})
+
+	if err := repo.Delete(ctx, "abc1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.FindByToken(ctx, "abc1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected link to be gone, got %v", err)
+	}
+	if err := repo.Delete(ctx, "abc1"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a second delete, got %v", err)
+	}
+}
+
+func testDeleteExpired(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	current := now()
+	old := current.Add(-30 * 24 * time.Hour)
+	exhausted := &entity.ShortURL{Token: "used", FullURL: "https://a", CreatedAt: current, MaxDownloads: 1}
+	links := []*entity.ShortURL{
+		{Token: "fresh", FullURL: "https://a", CreatedAt: current},
+		{Token: "old", FullURL: "https://a", CreatedAt: old},
+		{Token: "expired", FullURL: "https://a", CreatedAt: current, ExpiresAt: current.Add(-time.Minute)},
+		{Token: "later", FullURL: "https://a", CreatedAt: current, ExpiresAt: current.Add(time.Hour)},
+		exhausted,
+	}
+	for _, link := range links {
+		save(t, repo, link)
+	}
+	if err := repo.ConsumeDownload(ctx, "used"); err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	deleted, err := repo.DeleteExpired(ctx, current.Add(-14*24*time.Hour), current)
+	if err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("expected 3 deleted, got %d", deleted)
+	}
+	for token, kept := range map[string]bool{"fresh": true, "later": true, "old": false, "expired": false, "used": false} {
+		_, err := repo.FindByToken(ctx, token)
+		if kept && err != nil {
+			t.Errorf("expected %s to be kept, got %v", token, err)
+		}
+		if !kept && !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("expected %s to be purged, got %v", token, err)
+		}
+	}
+
+	// A zero cutoff only removes links past their own expiry
+	deleted, err = repo.DeleteExpired(ctx, time.Time{}, current.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected only the expired link to be deleted, got %d", deleted)
+	}
+}
+
+func testConsumeDownload(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	save(t, repo, &entity.ShortURL{Token: "limited", FullURL: "https://a", CreatedAt: now(), MaxDownloads: 2})
+	save(t, repo, &entity.ShortURL{Token: "open", FullURL: "https://a", CreatedAt: now()})
+
+	for i := range 2 {
+		if err := repo.ConsumeDownload(ctx, "limited"); err != nil {
+			t.Fatalf("download %d: %v", i+1, err)
+		}
+	}
+	if err := repo.ConsumeDownload(ctx, "limited"); !errors.Is(err, repository.ErrDownloadLimitReached) {
+		t.Errorf("expected ErrDownloadLimitReached, got %v", err)
+	}
+	if err := repo.ConsumeDownload(ctx, "open"); !errors.Is(err, repository.ErrDownloadLimitReached) {
+		t.Errorf("expected links without a limit not to be counted, got %v", err)
+	}
+
+	got, err := repo.FindByToken(ctx, "limited")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.Downloads != 2 || !got.DownloadsExhausted() {
+		t.Errorf("expected 2 of 2 downloads used, got %d of %d", got.Downloads, got.MaxDownloads)
+	}
+
+	if err := repo.ReleaseDownload(ctx, "limited"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := repo.ConsumeDownload(ctx, "limited"); err != nil {
+		t.Errorf("expected the released download to be available again, got %v", err)
+	}
+}
+
+func testRecordHitsAndStats(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	current := now()
+	save(t, repo, &entity.ShortURL{Token: "abc1", FullURL: "https://a", CreatedAt: current})
+
+	stats, err := repo.Stats(ctx, "abc1")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Hits != 0 || !stats.LastAccess.IsZero() {
+		t.Errorf("expected no hits yet, got %+v", stats)
+	}
+
+	yesterday := current.Add(-24 * time.Hour)
+	err = repo.RecordHits(ctx, []entity.Hit{
+		{Token: "abc1", At: current, Referrer: "direct", Client: entity.ClientCLI},
+		{Token: "abc1", At: yesterday, Referrer: "app.slack.com", Client: entity.ClientBrowser},
+		{Token: "abc1", At: current, Referrer: "direct", Client: entity.ClientCLI},
+	})
+	if err != nil {
+		t.Fatalf("record hits: %v", err)
+	}
+
+	stats, err = repo.Stats(ctx, "abc1")
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Hits != 3 {
+		t.Errorf("expected 3 hits, got %d", stats.Hits)
+	}
+	if !stats.LastAccess.Equal(current) {
+		t.Errorf("expected last access %v, got %v", current, stats.LastAccess)
+	}
+	if stats.Referrers["direct"] != 2 || stats.Referrers["app.slack.com"] != 1 {
+		t.Errorf("unexpected referrers %v", stats.Referrers)
+	}
+	if stats.Clients[entity.ClientCLI] != 2 || stats.Clients[entity.ClientBrowser] != 1 {
+		t.Errorf("unexpected clients %v", stats.Clients)
+	}
+	today := entity.Hit{At: current}.Day()
+	if stats.Days[today] != 2 || stats.Days[entity.Hit{At: yesterday}.Day()] != 1 {
+		t.Errorf("unexpected days %v", stats.Days)
+	}
+}
+
+func testDeleteDropsStats(t *testing.T, repo repository.URLRepository) {
+	ctx := context.Background()
+	current := now()
+	save(t, repo, &entity.ShortURL{Token: "gone", FullURL: "https://a", CreatedAt: current})
+	hits := []entity.Hit{
+		{Token: "gone", At: current, Referrer: "direct", Client: entity.ClientCLI},
+		{Token: "never", At: current, Referrer: "direct", Client: entity.ClientCLI},
+	}
+	if err := repo.RecordHits(ctx, hits); err != nil {
+		t.Fatalf("record hits: %v", err)
+	}
+
+	if err := repo.Delete(ctx, "gone"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.DeleteExpired(ctx, time.Time{}, current); err != nil {
+		t.Fatalf("delete expired: %v", err)
+	}
+
+	for _, token := range []string{"gone", "never"} {
+		stats, err := repo.Stats(ctx, token)
+		if err != nil {
+			t.Fatalf("stats: %v", err)
+		}
+		if stats.Hits != 0 || len(stats.Clients) != 0 {
+			t.Errorf("expected statistics of %s to be dropped, got %+v", token, stats)
+		}
+	}
+}
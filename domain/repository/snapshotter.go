@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"io"
+)
+
+// Snapshotter is implemented by stores that can write a consistent copy of
+// themselves while serving requests.
+type Snapshotter interface {
+	Snapshot(ctx context.Context, w io.Writer) error
+}
@@ -2,11 +2,44 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"transfer-shortener/domain/entity"
 )
 
+var (
+	ErrNotFound = errors.New("short URL not found")
+	// ErrDuplicateToken is returned by Save when the token is already taken.
+	ErrDuplicateToken = errors.New("token already exists")
+	// ErrDownloadLimitReached is returned by ConsumeDownload once a link has
+	// no downloads left.
+	ErrDownloadLimitReached = errors.New("download limit reached")
+	// ErrSchemaTooNew is returned when opening a database last migrated by a
+	// newer binary.
+	ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+)
+
 type URLRepository interface {
 	Save(ctx context.Context, shortURL *entity.ShortURL) error
+	// SaveAll stores all links atomically; if any token is taken, none are
+	// stored and ErrDuplicateToken is returned.
+	SaveAll(ctx context.Context, shortURLs []*entity.ShortURL) error
 	FindByToken(ctx context.Context, token string) (*entity.ShortURL, error)
+	// Delete removes the link, returning ErrNotFound if it does not exist.
+	Delete(ctx context.Context, token string) error
+	// ConsumeDownload counts one download of a limited link, atomically
+	// refusing with ErrDownloadLimitReached when none are left.
+	ConsumeDownload(ctx context.Context, token string) error
+	// ReleaseDownload gives back a download counted by ConsumeDownload.
+	ReleaseDownload(ctx context.Context, token string) error
+	// DeleteExpired removes links created before createdBefore (skipped when
+	// zero), links whose expiry is at or before now and links with no
+	// downloads left. It returns the number of removed links.
+	DeleteExpired(ctx context.Context, createdBefore, now time.Time) (int64, error)
+	// RecordHits adds the hits to their links' statistics.
+	RecordHits(ctx context.Context, hits []entity.Hit) error
+	// Stats returns the statistics of a link; a link without hits has zero
+	// counts rather than ErrNotFound.
+	Stats(ctx context.Context, token string) (*entity.LinkStats, error)
 }
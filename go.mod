@@ -2,7 +2,11 @@ module transfer-shortener
 
 go 1.24.0
 
-require modernc.org/sqlite v1.44.3
+require (
+	github.com/lib/pq v1.12.3
+	modernc.org/sqlite v1.44.3
+	rsc.io/qr v0.2.0
+)
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
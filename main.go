@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"transfer-shortener/adapter/cache"
 	httpAdapter "transfer-shortener/adapter/http"
+	"transfer-shortener/adapter/postgres"
 	"transfer-shortener/adapter/sqlite"
+	"transfer-shortener/domain/repository"
 	"transfer-shortener/usecase"
 )
 
@@ -19,26 +29,142 @@ var (
 func main() {
 	config := loadConfig()
 
-	repo, err := sqlite.NewRepository(config.DBPath)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(ctx, config, os.Args[2:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+		case "backup":
+			if err := runBackup(ctx, config, os.Args[2:]); err != nil {
+				log.Fatalf("backup: %v", err)
+			}
+		case "restore":
+			if err := runRestore(ctx, config, os.Args[2:]); err != nil {
+				log.Fatalf("restore: %v", err)
+			}
+		default:
+			log.Fatalf("unknown command %q", os.Args[1])
+		}
+		return
+	}
+
+	repo, err := openRepository(config)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer repo.Close()
 
-	createUC := usecase.NewCreateShortURL(repo)
-	resolveUC := usecase.NewResolveShortURL(repo)
-	proxy := httpAdapter.NewTransferProxy(config.BackendURL, config.PublicURL)
+	links := repository.URLRepository(repo)
+	var linkCache *cache.Repository
+	if config.CacheSize > 0 {
+		linkCache = cache.NewRepository(repo,
+			cache.WithSize(config.CacheSize),
+			cache.WithTTL(config.CacheTTL),
+			cache.WithNegativeTTL(config.CacheNegativeTTL),
+		)
+		links = linkCache
+	}
+
+	createUC := usecase.NewCreateShortURL(links)
+	resolveUC := usecase.NewResolveShortURL(links)
+	purgeUC := usecase.NewPurgeExpiredURLs(links, time.Duration(config.PurgeDays)*24*time.Hour)
+	proxy := httpAdapter.NewTransferProxy(config.BackendURL, config.PublicURL,
+		httpAdapter.WithTimeouts(config.ProxyTimeouts),
+	)
+	deleteUC := usecase.NewDeleteShortURL(links, proxy)
+	hitsUC := usecase.NewRecordHits(links, config.HitBufferSize, config.HitFlushInterval)
+	statsUC := usecase.NewGetLinkStats(links)
+	downloadsUC := usecase.NewConsumeDownload(links)
+	burnUC := usecase.NewBurnShortURL(proxy)
+	passwordUC := usecase.NewVerifyPassword(config.PasswordMaxFailures, config.PasswordLockout)
 
-	handler := httpAdapter.NewHandler(createUC, resolveUC, proxy, config.PublicURL)
+	resolveMode, err := httpAdapter.ParseResolveMode(config.ResolveMode)
+	if err != nil {
+		log.Fatalf("Invalid RESOLVE_MODE: %v", err)
+	}
+
+	var cacheStats httpAdapter.CacheStatsSource
+	if linkCache != nil {
+		cacheStats = cacheStatsSource{links: linkCache}
+	}
+
+	var backupUC httpAdapter.BackupUseCase
+	if store, ok := repo.(repository.Snapshotter); ok {
+		backupUC = usecase.NewBackupDatabase(store)
+	} else if config.AdminToken != "" {
+		log.Printf("Admin backups are not supported with DB_DRIVER=%s", config.DBDriver)
+	}
+
+	handler := httpAdapter.NewHandler(createUC, resolveUC, proxy, config.PublicURL,
+		httpAdapter.WithDeleteShortURL(deleteUC),
+		httpAdapter.WithResolveMode(resolveMode),
+		httpAdapter.WithHitRecorder(hitsUC),
+		httpAdapter.WithLinkStats(statsUC),
+		httpAdapter.WithDownloadLimits(downloadsUC),
+		httpAdapter.WithPasswords(passwordUC),
+		httpAdapter.WithBurnAfterReading(burnUC),
+		httpAdapter.WithBackup(backupUC, config.AdminToken),
+		httpAdapter.WithCacheStats(cacheStats, config.AdminToken),
+	)
 
 	log.Printf("transfer-shortener version=%s commit=%s built=%s", version, commit, buildTime)
 	log.Printf("Starting server on %s", config.ListenAddr)
 	log.Printf("Backend: %s", config.BackendURL)
 	log.Printf("Public URL: %s", config.PublicURL)
+	log.Printf("Resolve mode: %s", resolveMode)
+	log.Printf("Database driver: %s", config.DBDriver)
+
+	reaperDone := make(chan struct{})
+	go func() {
+		defer close(reaperDone)
+		runReaper(ctx, purgeUC, config.ReaperInterval)
+	}()
+
+	cacheStatsDone := make(chan struct{})
+	go func() {
+		defer close(cacheStatsDone)
+		if linkCache != nil {
+			runCacheStats(ctx, linkCache, config.CacheStatsInterval)
+		}
+	}()
+
+	// Hits keep being recorded while in-flight requests drain, so the recorder
+	// stops only after the server has shut down.
+	hitsCtx, stopHits := context.WithCancel(context.Background())
+	hitsDone := make(chan struct{})
+	go func() {
+		defer close(hitsDone)
+		hitsUC.Run(hitsCtx)
+	}()
 
-	if err := http.ListenAndServe(config.ListenAddr, handler); err != nil {
+	server := &http.Server{Addr: config.ListenAddr, Handler: handler}
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown error: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Server failed: %v", err)
 	}
+	<-shutdownDone
+	stopHits()
+	<-hitsDone
+	if dropped := hitsUC.Dropped(); dropped > 0 {
+		log.Printf("hits: dropped %d hits on a full buffer", dropped)
+	}
+	<-reaperDone
+	<-cacheStatsDone
+	log.Printf("Server stopped")
 }
 
 type Config struct {
@@ -46,20 +172,130 @@ type Config struct {
 	BackendURL string
 	PublicURL  string
 	DBPath     string
+	// DBDriver is sqlite or postgres; DBDSN is the Postgres connection string
+	// and overrides DBPath for SQLite.
+	DBDriver         string
+	DBDSN            string
+	SQLiteTuning     sqlite.Tuning
+	ResolveMode      string
+	PurgeDays        int
+	ReaperInterval   time.Duration
+	ProxyTimeouts    httpAdapter.Timeouts
+	HitBufferSize    int
+	HitFlushInterval time.Duration
+	// PasswordMaxFailures wrong passwords within PasswordLockout lock a link
+	// until the window has passed.
+	PasswordMaxFailures int
+	PasswordLockout     time.Duration
+	// CacheSize is the number of short links kept in memory; 0 disables the
+	// cache.
+	CacheSize          int
+	CacheTTL           time.Duration
+	CacheNegativeTTL   time.Duration
+	CacheStatsInterval time.Duration
+	// AdminToken authorizes /api/v1/admin/ requests; empty disables them.
+	AdminToken string
 }
 
 func loadConfig() Config {
+	defaults := httpAdapter.DefaultTimeouts()
+	tuning := sqlite.DefaultTuning()
+	driver := getEnv("DB_DRIVER", "sqlite")
+	cacheTTL, cacheNegativeTTL := 5*time.Minute, 30*time.Second
+	if driver == "postgres" {
+		// Replicas only see each other's deletes and new aliases once their
+		// cached entries expire.
+		cacheTTL, cacheNegativeTTL = 5*time.Second, 5*time.Second
+	}
 	return Config{
 		ListenAddr: getEnv("LISTEN_ADDR", ":8080"),
 		BackendURL: getEnv("BACKEND_URL", "http://transfer:5327"),
 		PublicURL:  getEnv("PUBLIC_URL", "https://transfer.sixtyfive.me"),
 		DBPath:     getEnv("DB_PATH", "/data/shortener.db"),
+		DBDriver:   driver,
+		DBDSN:      os.Getenv("DB_DSN"),
+		SQLiteTuning: sqlite.Tuning{
+			JournalMode:  getEnv("SQLITE_JOURNAL_MODE", tuning.JournalMode),
+			Synchronous:  getEnv("SQLITE_SYNCHRONOUS", tuning.Synchronous),
+			BusyTimeout:  getEnvDuration("SQLITE_BUSY_TIMEOUT", tuning.BusyTimeout),
+			ForeignKeys:  getEnvBool("SQLITE_FOREIGN_KEYS", tuning.ForeignKeys),
+			MaxReadConns: getEnvInt("SQLITE_READ_CONNS", tuning.MaxReadConns),
+		},
+		ResolveMode:    getEnv("RESOLVE_MODE", string(httpAdapter.ResolveModeRedirect)),
+		PurgeDays:      getEnvInt("PURGE_DAYS", 0),
+		ReaperInterval: getEnvDuration("REAPER_INTERVAL", time.Hour),
+		ProxyTimeouts: httpAdapter.Timeouts{
+			Dial:           getEnvDuration("PROXY_DIAL_TIMEOUT", defaults.Dial),
+			TLSHandshake:   getEnvDuration("PROXY_TLS_TIMEOUT", defaults.TLSHandshake),
+			ResponseHeader: getEnvDuration("PROXY_RESPONSE_HEADER_TIMEOUT", defaults.ResponseHeader),
+			IdleConn:       getEnvDuration("PROXY_IDLE_CONN_TIMEOUT", defaults.IdleConn),
+		},
+		HitBufferSize:       getEnvInt("HIT_BUFFER_SIZE", 1024),
+		HitFlushInterval:    getEnvDuration("HIT_FLUSH_INTERVAL", 5*time.Second),
+		PasswordMaxFailures: getEnvInt("PASSWORD_MAX_FAILURES", 5),
+		PasswordLockout:     getEnvDuration("PASSWORD_LOCKOUT", 15*time.Minute),
+		CacheSize:           getEnvInt("CACHE_SIZE", 10000),
+		CacheTTL:            getEnvDuration("CACHE_TTL", cacheTTL),
+		CacheNegativeTTL:    getEnvDuration("CACHE_NEGATIVE_TTL", cacheNegativeTTL),
+		CacheStatsInterval:  getEnvDuration("CACHE_STATS_INTERVAL", time.Hour),
+		AdminToken:          os.Getenv("ADMIN_TOKEN"),
 	}
 }
 
+// closableRepository is a URLRepository owning a database connection.
+type closableRepository interface {
+	repository.URLRepository
+	Close() error
+}
+
+func openRepository(config Config) (closableRepository, error) {
+	switch config.DBDriver {
+	case "sqlite":
+		return sqlite.NewRepository(sqliteDSN(config), sqlite.WithTuning(config.SQLiteTuning))
+	case "postgres":
+		if config.DBDSN == "" {
+			return nil, errors.New("DB_DSN is required for DB_DRIVER=postgres")
+		}
+		return postgres.NewRepository(config.DBDSN)
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (want sqlite or postgres)", config.DBDriver)
+	}
+}
+
+func sqliteDSN(config Config) string {
+	if config.DBDSN != "" {
+		return config.DBDSN
+	}
+	return config.DBPath
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"transfer-shortener/adapter/sqlite"
+)
+
+const migrateUsage = "usage: shortener migrate status | shortener migrate up [--dry-run]"
+
+// runMigrate implements the migrate subcommand. Postgres migrates itself on
+// startup, one replica at a time.
+func runMigrate(ctx context.Context, config Config, args []string) error {
+	if config.DBDriver != "sqlite" {
+		return fmt.Errorf("migrate only manages sqlite databases, DB_DRIVER is %q", config.DBDriver)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	dsn := sqliteDSN(config)
+	tuning := sqlite.WithTuning(config.SQLiteTuning)
+
+	switch args[0] {
+	case "status":
+		status, err := sqlite.Status(ctx, dsn, tuning)
+		if err != nil {
+			return err
+		}
+		printSchemaStatus(os.Stdout, status)
+		if status.TooNew() {
+			return fmt.Errorf("%w: version %d, binary supports up to %d", sqlite.ErrSchemaTooNew, status.Version, status.Latest)
+		}
+		return nil
+
+	case "up":
+		flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+		dryRun := flags.Bool("dry-run", false, "list pending migrations without applying them")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if *dryRun {
+			status, err := sqlite.Status(ctx, dsn, tuning)
+			if err != nil {
+				return err
+			}
+			if status.TooNew() {
+				return fmt.Errorf("%w: version %d, binary supports up to %d", sqlite.ErrSchemaTooNew, status.Version, status.Latest)
+			}
+			if len(status.Pending) == 0 {
+				fmt.Printf("schema is up to date at version %d\n", status.Version)
+				return nil
+			}
+			for _, m := range status.Pending {
+				fmt.Printf("would apply %d %s\n", m.Version, m.Name)
+			}
+			return nil
+		}
+
+		applied, err := sqlite.Migrate(ctx, dsn, tuning)
+		for _, m := range applied {
+			fmt.Printf("applied %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func printSchemaStatus(w io.Writer, status sqlite.SchemaStatus) {
+	fmt.Fprintf(w, "schema version %d, binary supports %d\n", status.Version, status.Latest)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, applied := range status.Applied {
+		fmt.Fprintf(tw, "%d\t%s\tapplied %s\n", applied.Version, applied.Name, applied.AppliedAt.UTC().Format("2006-01-02 15:04:05"))
+	}
+	for _, m := range status.Pending {
+		fmt.Fprintf(tw, "%d\t%s\tpending\n", m.Version, m.Name)
+	}
+	tw.Flush()
+}
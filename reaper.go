@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"transfer-shortener/usecase"
+)
+
+// runReaper sweeps expired and over-retention links every interval until ctx
+// is cancelled. The first sweep runs immediately so a restart catches up.
+func runReaper(ctx context.Context, purgeUC *usecase.PurgeExpiredURLs, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var total int64
+	for {
+		start := time.Now()
+		deleted, err := purgeUC.Execute(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("reaper: sweep failed: %v", err)
+		} else if err == nil {
+			total += deleted
+			log.Printf("reaper: purged=%d total=%d duration=%s", deleted, total, time.Since(start).Round(time.Millisecond))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
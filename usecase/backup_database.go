@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"transfer-shortener/domain/repository"
+)
+
+type BackupDatabase struct {
+	store repository.Snapshotter
+}
+
+func NewBackupDatabase(store repository.Snapshotter) *BackupDatabase {
+	return &BackupDatabase{store: store}
+}
+
+func (uc *BackupDatabase) Execute(ctx context.Context, w io.Writer) error {
+	return uc.store.Snapshot(ctx, w)
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"transfer-shortener/domain/entity"
+)
+
+// BurnShortURL deletes the backend file of a one-time link once its single
+// download has been served, and of a limited link once its last one has. The
+// link itself stays, consumed, so it answers 410 Gone until the reaper
+// purges it.
+type BurnShortURL struct {
+	files FileDeleter
+}
+
+func NewBurnShortURL(files FileDeleter) *BurnShortURL {
+	return &BurnShortURL{files: files}
+}
+
+func (uc *BurnShortURL) Execute(ctx context.Context, shortURL *entity.ShortURL) error {
+	deleteURL := shortURL.DeleteURL()
+	if !(shortURL.BurnAfterReading || shortURL.DownloadsExhausted()) || deleteURL == "" {
+		return nil
+	}
+	if err := uc.files.DeleteFile(ctx, deleteURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrFileDeletion, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/usecase"
+)
+
+func TestBurnShortURL_DeletesBackendFile(t *testing.T) {
+	var deletedFile string
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			deletedFile = deleteURL
+			return nil
+		},
+	}
+	shortURL := &entity.ShortURL{
+		Token:       "abc1",
+		FullURL:     "https://transfer.sixtyfive.me/abc12/file.txt",
+		DeleteToken: "s3cr3t",
+	}
+	shortURL.MakeOneTime()
+
+	uc := usecase.NewBurnShortURL(files)
+
+	if err := uc.Execute(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deletedFile != "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t" {
+		t.Errorf("expected backend delete URL, got %q", deletedFile)
+	}
+}
+
+func TestBurnShortURL_DeletesExhaustedLimitedLink(t *testing.T) {
+	var deletedFile string
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			deletedFile = deleteURL
+			return nil
+		},
+	}
+	uc := usecase.NewBurnShortURL(files)
+
+	shortURL := &entity.ShortURL{
+		Token:        "abc1",
+		FullURL:      "https://transfer.sixtyfive.me/abc12/file.txt",
+		DeleteToken:  "s3cr3t",
+		MaxDownloads: 2,
+		Downloads:    1,
+	}
+	if err := uc.Execute(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deletedFile != "" {
+		t.Fatalf("expected the file to stay while a download is left, deleted %q", deletedFile)
+	}
+
+	shortURL.Downloads = 2
+	if err := uc.Execute(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deletedFile != "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t" {
+		t.Errorf("expected backend delete URL, got %q", deletedFile)
+	}
+}
+
+func TestBurnShortURL_KeepsRegularLinks(t *testing.T) {
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			t.Error("regular links must not delete their file")
+			return nil
+		},
+	}
+	shortURL := &entity.ShortURL{Token: "abc1", FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t"}
+
+	uc := usecase.NewBurnShortURL(files)
+
+	if err := uc.Execute(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestBurnShortURL_BackendFailure(t *testing.T) {
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			return errors.New("backend down")
+		},
+	}
+	shortURL := &entity.ShortURL{Token: "abc1", FullURL: "https://transfer.sixtyfive.me/abc12/file.txt", DeleteToken: "s3cr3t"}
+	shortURL.MakeOneTime()
+
+	uc := usecase.NewBurnShortURL(files)
+
+	if err := uc.Execute(context.Background(), shortURL); !errors.Is(err, usecase.ErrFileDeletion) {
+		t.Errorf("expected ErrFileDeletion, got %v", err)
+	}
+}
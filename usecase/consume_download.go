@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+)
+
+// ConsumeDownload counts a download against a link's Max-Downloads limit.
+type ConsumeDownload struct {
+	repo repository.URLRepository
+}
+
+func NewConsumeDownload(repo repository.URLRepository) *ConsumeDownload {
+	return &ConsumeDownload{repo: repo}
+}
+
+// Execute takes one download from shortURL's limit, returning ErrExpired once
+// the limit is used up. Links without a limit are not counted.
+func (uc *ConsumeDownload) Execute(ctx context.Context, shortURL *entity.ShortURL) error {
+	if shortURL.MaxDownloads <= 0 {
+		return nil
+	}
+
+	err := uc.repo.ConsumeDownload(ctx, shortURL.Token)
+	if errors.Is(err, repository.ErrDownloadLimitReached) {
+		return ErrExpired
+	}
+	if err != nil {
+		return err
+	}
+
+	shortURL.Downloads++
+	return nil
+}
+
+// Release gives back a download taken by Execute that never reached the
+// client, e.g. because the backend failed.
+func (uc *ConsumeDownload) Release(ctx context.Context, shortURL *entity.ShortURL) error {
+	if shortURL.MaxDownloads <= 0 {
+		return nil
+	}
+	if err := uc.repo.ReleaseDownload(ctx, shortURL.Token); err != nil {
+		return err
+	}
+	shortURL.Downloads--
+	return nil
+}
@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/usecase"
+)
+
+func TestConsumeDownload_CountsLimitedLink(t *testing.T) {
+	var consumed string
+	repo := &mockURLRepository{
+		consumeFunc: func(ctx context.Context, token string) error {
+			consumed = token
+			return nil
+		},
+	}
+	shortURL := &entity.ShortURL{Token: "abc1", MaxDownloads: 2}
+
+	uc := usecase.NewConsumeDownload(repo)
+
+	if err := uc.Execute(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if consumed != "abc1" {
+		t.Errorf("expected abc1 to be consumed, got %q", consumed)
+	}
+	if got := shortURL.RemainingDownloads(); got != 1 {
+		t.Errorf("expected 1 remaining download, got %d", got)
+	}
+}
+
+func TestConsumeDownload_LimitReached(t *testing.T) {
+	repo := &mockURLRepository{
+		consumeFunc: func(ctx context.Context, token string) error {
+			return repository.ErrDownloadLimitReached
+		},
+	}
+
+	uc := usecase.NewConsumeDownload(repo)
+
+	err := uc.Execute(context.Background(), &entity.ShortURL{Token: "abc1", MaxDownloads: 1})
+
+	if !errors.Is(err, usecase.ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestConsumeDownload_UnlimitedLinkNotCounted(t *testing.T) {
+	repo := &mockURLRepository{
+		consumeFunc: func(ctx context.Context, token string) error {
+			t.Error("unlimited link must not be counted")
+			return nil
+		},
+	}
+
+	uc := usecase.NewConsumeDownload(repo)
+
+	if err := uc.Execute(context.Background(), &entity.ShortURL{Token: "abc1"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestConsumeDownload_Release(t *testing.T) {
+	var released string
+	repo := &mockURLRepository{
+		releaseFunc: func(ctx context.Context, token string) error {
+			released = token
+			return nil
+		},
+	}
+	shortURL := &entity.ShortURL{Token: "abc1", MaxDownloads: 1, Downloads: 1}
+
+	uc := usecase.NewConsumeDownload(repo)
+
+	if err := uc.Release(context.Background(), shortURL); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if released != "abc1" {
+		t.Errorf("expected abc1 to be released, got %q", released)
+	}
+	if got := shortURL.RemainingDownloads(); got != 1 {
+		t.Errorf("expected 1 remaining download, got %d", got)
+	}
+}
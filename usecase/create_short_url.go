@@ -2,28 +2,159 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 
 	"transfer-shortener/domain/entity"
 	"transfer-shortener/domain/repository"
 )
 
+// attemptsPerTokenLength bounds how many random tokens are tried at one length
+// before the keyspace is considered crowded and the length grows.
+const attemptsPerTokenLength = 3
+
+var (
+	ErrTokenSpaceExhausted = errors.New("no free short token available")
+	ErrAliasTaken          = errors.New("alias is already in use")
+)
+
+type CreateShortURLInput struct {
+	FullURL string
+	// MaxDays mirrors the transfer.sh Max-Days upload header; 0 means no expiry.
+	MaxDays int
+	// DeleteToken is the transfer.sh deletion secret issued for the upload.
+	DeleteToken string
+	// Alias requests a vanity token instead of a random one.
+	Alias string
+	// MaxDownloads limits how often the link may be downloaded; 0 means no limit.
+	MaxDownloads int
+	// Password gates the link; empty leaves it open.
+	Password string
+	// Once makes the link resolvable a single time, deleting the file after.
+	Once bool
+}
+
 type CreateShortURL struct {
 	repo repository.URLRepository
+	// tokenLength only ever grows, so once the keyspace is crowded new links
+	// keep using the longer tokens.
+	tokenLength atomic.Int32
 }
 
 func NewCreateShortURL(repo repository.URLRepository) *CreateShortURL {
-	return &CreateShortURL{repo: repo}
+	uc := &CreateShortURL{repo: repo}
+	uc.tokenLength.Store(entity.DefaultTokenLength)
+	return uc
 }
 
-func (uc *CreateShortURL) Execute(ctx context.Context, fullURL string) (*entity.ShortURL, error) {
-	shortURL, err := entity.NewShortURL(fullURL)
+func (uc *CreateShortURL) Execute(ctx context.Context, input CreateShortURLInput) (*entity.ShortURL, error) {
+	shortURLs, err := uc.ExecuteBatch(ctx, []CreateShortURLInput{input})
 	if err != nil {
 		return nil, err
 	}
+	return shortURLs[0], nil
+}
+
+// ExecuteBatch creates one link per input, in order, and stores them all in a
+// single transaction so a multi-file upload never ends up half shortened.
+func (uc *CreateShortURL) ExecuteBatch(ctx context.Context, inputs []CreateShortURLInput) ([]*entity.ShortURL, error) {
+	length := int(uc.tokenLength.Load())
+
+	shortURLs := make([]*entity.ShortURL, 0, len(inputs))
+	for _, input := range inputs {
+		var shortURL *entity.ShortURL
+		var err error
+		if input.Alias != "" {
+			shortURL, err = entity.NewShortURLWithAlias(input.FullURL, input.Alias)
+		} else {
+			shortURL, err = entity.NewShortURLWithTokenLength(input.FullURL, length)
+		}
+		if err != nil {
+			return nil, err
+		}
+		shortURL.ExpireAfterDays(input.MaxDays)
+		shortURL.DeleteToken = input.DeleteToken
+		shortURL.MaxDownloads = max(input.MaxDownloads, 0)
+		if input.Once {
+			shortURL.MakeOneTime()
+		}
+		if err := shortURL.SetPassword(input.Password); err != nil {
+			return nil, err
+		}
+		shortURLs = append(shortURLs, shortURL)
+	}
 
-	if err := uc.repo.Save(ctx, shortURL); err != nil {
+	if err := uc.saveAll(ctx, shortURLs, inputs, length); err != nil {
 		return nil, err
 	}
 
-	return shortURL, nil
+	return shortURLs, nil
+}
+
+// CheckAlias reports whether alias is valid and still free, so uploads can be
+// rejected before the file is sent to the backend.
+func (uc *CreateShortURL) CheckAlias(ctx context.Context, alias string) error {
+	if err := entity.ValidateAlias(alias); err != nil {
+		return err
+	}
+
+	_, err := uc.repo.FindByToken(ctx, alias)
+	switch {
+	case err == nil:
+		return ErrAliasTaken
+	case errors.Is(err, repository.ErrNotFound):
+		return nil
+	default:
+		return err
+	}
+}
+
+// saveAll stores shortURLs, drawing fresh random tokens whenever one is
+// already taken and growing the token length when collisions keep happening.
+// Aliases are never changed; a taken alias fails the whole batch.
+func (uc *CreateShortURL) saveAll(ctx context.Context, shortURLs []*entity.ShortURL, inputs []CreateShortURLInput, length int) error {
+	for {
+		for attempt := 0; attempt < attemptsPerTokenLength; attempt++ {
+			if attempt > 0 {
+				if err := regenerateRandomTokens(shortURLs, inputs, length); err != nil {
+					return err
+				}
+			}
+
+			err := uc.repo.SaveAll(ctx, shortURLs)
+			if !errors.Is(err, repository.ErrDuplicateToken) {
+				return err
+			}
+
+			for _, input := range inputs {
+				if input.Alias == "" {
+					continue
+				}
+				if err := uc.CheckAlias(ctx, input.Alias); err != nil {
+					return err
+				}
+			}
+		}
+
+		if length >= entity.MaxTokenLength {
+			return ErrTokenSpaceExhausted
+		}
+		uc.tokenLength.CompareAndSwap(int32(length), int32(length+1))
+		length = int(uc.tokenLength.Load())
+		if err := regenerateRandomTokens(shortURLs, inputs, length); err != nil {
+			return err
+		}
+	}
+}
+
+func regenerateRandomTokens(shortURLs []*entity.ShortURL, inputs []CreateShortURLInput, length int) error {
+	for i, shortURL := range shortURLs {
+		if inputs[i].Alias != "" {
+			continue
+		}
+		if err := shortURL.RegenerateToken(length); err != nil {
+			return err
+		}
+	}
+	return nil
 }
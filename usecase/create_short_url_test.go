@@ -4,14 +4,23 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
 	"transfer-shortener/usecase"
 )
 
 type mockURLRepository struct {
-	saveFunc       func(ctx context.Context, shortURL *entity.ShortURL) error
-	findByTokenFunc func(ctx context.Context, token string) (*entity.ShortURL, error)
+	saveFunc          func(ctx context.Context, shortURL *entity.ShortURL) error
+	saveAllFunc       func(ctx context.Context, shortURLs []*entity.ShortURL) error
+	findByTokenFunc   func(ctx context.Context, token string) (*entity.ShortURL, error)
+	deleteFunc        func(ctx context.Context, token string) error
+	consumeFunc       func(ctx context.Context, token string) error
+	releaseFunc       func(ctx context.Context, token string) error
+	deleteExpiredFunc func(ctx context.Context, createdBefore, now time.Time) (int64, error)
+	recordHitsFunc    func(ctx context.Context, hits []entity.Hit) error
+	statsFunc         func(ctx context.Context, token string) (*entity.LinkStats, error)
 }
 
 func (m *mockURLRepository) Save(ctx context.Context, shortURL *entity.ShortURL) error {
@@ -21,6 +30,19 @@ func (m *mockURLRepository) Save(ctx context.Context, shortURL *entity.ShortURL)
 	return nil
 }
 
+// SaveAll falls back to saveFunc per link so single-link tests only need one hook.
+func (m *mockURLRepository) SaveAll(ctx context.Context, shortURLs []*entity.ShortURL) error {
+	if m.saveAllFunc != nil {
+		return m.saveAllFunc(ctx, shortURLs)
+	}
+	for _, shortURL := range shortURLs {
+		if err := m.Save(ctx, shortURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *mockURLRepository) FindByToken(ctx context.Context, token string) (*entity.ShortURL, error) {
 	if m.findByTokenFunc != nil {
 		return m.findByTokenFunc(ctx, token)
@@ -28,6 +50,48 @@ func (m *mockURLRepository) FindByToken(ctx context.Context, token string) (*ent
 	return nil, errors.New("not found")
 }
 
+func (m *mockURLRepository) Delete(ctx context.Context, token string) error {
+	if m.deleteFunc != nil {
+		return m.deleteFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *mockURLRepository) ConsumeDownload(ctx context.Context, token string) error {
+	if m.consumeFunc != nil {
+		return m.consumeFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *mockURLRepository) ReleaseDownload(ctx context.Context, token string) error {
+	if m.releaseFunc != nil {
+		return m.releaseFunc(ctx, token)
+	}
+	return nil
+}
+
+func (m *mockURLRepository) DeleteExpired(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+	if m.deleteExpiredFunc != nil {
+		return m.deleteExpiredFunc(ctx, createdBefore, now)
+	}
+	return 0, nil
+}
+
+func (m *mockURLRepository) RecordHits(ctx context.Context, hits []entity.Hit) error {
+	if m.recordHitsFunc != nil {
+		return m.recordHitsFunc(ctx, hits)
+	}
+	return nil
+}
+
+func (m *mockURLRepository) Stats(ctx context.Context, token string) (*entity.LinkStats, error) {
+	if m.statsFunc != nil {
+		return m.statsFunc(ctx, token)
+	}
+	return &entity.LinkStats{Token: token}, nil
+}
+
 func TestCreateShortURL_Success(t *testing.T) {
 	var savedURL *entity.ShortURL
 	repo := &mockURLRepository{
@@ -40,7 +104,7 @@ func TestCreateShortURL_Success(t *testing.T) {
 	uc := usecase.NewCreateShortURL(repo)
 	fullURL := "https://transfer.sixtyfive.me/abc12/file.txt"
 
-	result, err := uc.Execute(context.Background(), fullURL)
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: fullURL})
 
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
@@ -54,13 +118,57 @@ func TestCreateShortURL_Success(t *testing.T) {
 	if savedURL == nil {
 		t.Error("expected Save to be called")
 	}
+	if !result.ExpiresAt.IsZero() {
+		t.Errorf("expected no expiry without MaxDays, got %v", result.ExpiresAt)
+	}
+}
+
+func TestCreateShortURL_MaxDaysSetsExpiry(t *testing.T) {
+	repo := &mockURLRepository{}
+	uc := usecase.NewCreateShortURL(repo)
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL: "https://example.com/abc12/file.txt",
+		MaxDays: 7,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := result.CreatedAt.AddDate(0, 0, 7)
+	if !result.ExpiresAt.Equal(expected) {
+		t.Errorf("expected ExpiresAt %v, got %v", expected, result.ExpiresAt)
+	}
+}
+
+func TestCreateShortURL_StoresDeleteToken(t *testing.T) {
+	var savedURL *entity.ShortURL
+	repo := &mockURLRepository{
+		saveFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			savedURL = shortURL
+			return nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	_, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL:     "https://example.com/abc12/file.txt",
+		DeleteToken: "s3cr3t",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if savedURL.DeleteToken != "s3cr3t" {
+		t.Errorf("expected DeleteToken s3cr3t, got %q", savedURL.DeleteToken)
+	}
 }
 
 func TestCreateShortURL_InvalidURL(t *testing.T) {
 	repo := &mockURLRepository{}
 	uc := usecase.NewCreateShortURL(repo)
 
-	_, err := uc.Execute(context.Background(), "invalid-url")
+	_, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "invalid-url"})
 
 	if err == nil {
 		t.Error("expected error for invalid URL, got nil")
@@ -75,9 +183,272 @@ func TestCreateShortURL_RepositoryError(t *testing.T) {
 	}
 	uc := usecase.NewCreateShortURL(repo)
 
-	_, err := uc.Execute(context.Background(), "https://example.com/file.txt")
+	_, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "https://example.com/file.txt"})
 
 	if err == nil {
 		t.Error("expected error when repository fails, got nil")
 	}
 }
+
+func TestCreateShortURL_RetriesOnDuplicateToken(t *testing.T) {
+	var tokens []string
+	repo := &mockURLRepository{
+		saveFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			tokens = append(tokens, shortURL.Token)
+			if len(tokens) < 3 {
+				return repository.ErrDuplicateToken
+			}
+			return nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "https://example.com/file.txt"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(tokens) != 3 {
+		t.Errorf("expected 3 save attempts, got %d", len(tokens))
+	}
+	if result.Token != tokens[len(tokens)-1] {
+		t.Errorf("expected result token %s to be the saved one %s", result.Token, tokens[len(tokens)-1])
+	}
+	if len(result.Token) != entity.DefaultTokenLength {
+		t.Errorf("expected token length %d, got %d", entity.DefaultTokenLength, len(result.Token))
+	}
+}
+
+func TestCreateShortURL_GrowsTokenLengthWhenCrowded(t *testing.T) {
+	collisions := 0
+	repo := &mockURLRepository{
+		saveFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			if len(shortURL.Token) == entity.DefaultTokenLength {
+				collisions++
+				return repository.ErrDuplicateToken
+			}
+			return nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "https://example.com/file.txt"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Token) != entity.DefaultTokenLength+1 {
+		t.Errorf("expected token length %d, got %d", entity.DefaultTokenLength+1, len(result.Token))
+	}
+
+	// The grown length sticks for later links.
+	next, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "https://example.com/other.txt"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(next.Token) != entity.DefaultTokenLength+1 {
+		t.Errorf("expected later token length %d, got %d", entity.DefaultTokenLength+1, len(next.Token))
+	}
+	if collisions != 3 {
+		t.Errorf("expected 3 collisions before growing, got %d", collisions)
+	}
+}
+
+func TestCreateShortURL_TokenSpaceExhausted(t *testing.T) {
+	repo := &mockURLRepository{
+		saveFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			return repository.ErrDuplicateToken
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	_, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{FullURL: "https://example.com/file.txt"})
+
+	if !errors.Is(err, usecase.ErrTokenSpaceExhausted) {
+		t.Errorf("expected ErrTokenSpaceExhausted, got %v", err)
+	}
+}
+
+func TestCreateShortURL_WithAlias(t *testing.T) {
+	repo := &mockURLRepository{}
+	uc := usecase.NewCreateShortURL(repo)
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL: "https://example.com/abc12/notes.md",
+		Alias:   "release-notes",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Token != "release-notes" {
+		t.Errorf("expected Token release-notes, got %s", result.Token)
+	}
+}
+
+func TestCreateShortURL_AliasConflict(t *testing.T) {
+	attempts := 0
+	repo := &mockURLRepository{
+		saveFunc: func(ctx context.Context, shortURL *entity.ShortURL) error {
+			attempts++
+			return repository.ErrDuplicateToken
+		},
+		findByTokenFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{Token: token}, nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	_, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL: "https://example.com/abc12/notes.md",
+		Alias:   "release-notes",
+	})
+
+	if !errors.Is(err, usecase.ErrAliasTaken) {
+		t.Errorf("expected ErrAliasTaken, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected aliases not to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestCreateShortURL_CheckAlias(t *testing.T) {
+	repo := &mockURLRepository{
+		findByTokenFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token == "taken" {
+				return &entity.ShortURL{Token: token}, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	tests := []struct {
+		alias    string
+		expected error
+	}{
+		{"free-alias", nil},
+		{"taken", usecase.ErrAliasTaken},
+		{"health", entity.ErrReservedAlias},
+		{"a!", entity.ErrInvalidAlias},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			if err := uc.CheckAlias(context.Background(), tt.alias); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestCreateShortURL_ExecuteBatch(t *testing.T) {
+	var saved []*entity.ShortURL
+	saveCalls := 0
+	repo := &mockURLRepository{
+		saveAllFunc: func(ctx context.Context, shortURLs []*entity.ShortURL) error {
+			saveCalls++
+			saved = shortURLs
+			return nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	results, err := uc.ExecuteBatch(context.Background(), []usecase.CreateShortURLInput{
+		{FullURL: "https://example.com/abc12/a.log", MaxDays: 2},
+		{FullURL: "https://example.com/def34/b.log", MaxDays: 2},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if saveCalls != 1 || len(saved) != 2 {
+		t.Fatalf("expected both links in one SaveAll call, got %d calls with %d links", saveCalls, len(saved))
+	}
+	if results[0].FullURL != "https://example.com/abc12/a.log" || results[1].FullURL != "https://example.com/def34/b.log" {
+		t.Errorf("expected results in input order, got %s, %s", results[0].FullURL, results[1].FullURL)
+	}
+	if results[0].Token == results[1].Token {
+		t.Error("expected distinct tokens per file")
+	}
+	if results[1].ExpiresAt.IsZero() {
+		t.Error("expected MaxDays to apply to every link")
+	}
+}
+
+func TestCreateShortURL_ExecuteBatch_RetriesWholeBatchOnDuplicate(t *testing.T) {
+	attempts := 0
+	repo := &mockURLRepository{
+		saveAllFunc: func(ctx context.Context, shortURLs []*entity.ShortURL) error {
+			attempts++
+			if attempts == 1 {
+				return repository.ErrDuplicateToken
+			}
+			return nil
+		},
+	}
+	uc := usecase.NewCreateShortURL(repo)
+
+	results, err := uc.ExecuteBatch(context.Background(), []usecase.CreateShortURLInput{
+		{FullURL: "https://example.com/abc12/a.log"},
+		{FullURL: "https://example.com/def34/b.log"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 results, got %d", len(results))
+	}
+}
+
+func TestCreateShortURL_MaxDownloads(t *testing.T) {
+	uc := usecase.NewCreateShortURL(&mockURLRepository{})
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL:      "https://transfer.sixtyfive.me/abc12/file.txt",
+		MaxDownloads: 3,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.MaxDownloads != 3 || result.Downloads != 0 {
+		t.Errorf("expected 3 downloads allowed and none used, got %d/%d", result.Downloads, result.MaxDownloads)
+	}
+}
+
+func TestCreateShortURL_Password(t *testing.T) {
+	uc := usecase.NewCreateShortURL(&mockURLRepository{})
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL:  "https://transfer.sixtyfive.me/abc12/file.txt",
+		Password: "s3cr3t",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.HasPassword() || !result.CheckPassword("s3cr3t") {
+		t.Error("expected link to be protected by the password")
+	}
+}
+
+func TestCreateShortURL_Once(t *testing.T) {
+	uc := usecase.NewCreateShortURL(&mockURLRepository{})
+
+	result, err := uc.Execute(context.Background(), usecase.CreateShortURLInput{
+		FullURL: "https://transfer.sixtyfive.me/abc12/file.txt",
+		Once:    true,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.BurnAfterReading || result.MaxDownloads != 1 {
+		t.Errorf("expected a one-time link, got %+v", result)
+	}
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"transfer-shortener/domain/repository"
+)
+
+var (
+	ErrInvalidDeleteToken = errors.New("invalid deletion token")
+	ErrFileDeletion       = errors.New("failed to delete backend file")
+)
+
+// FileDeleter removes an uploaded file from the transfer.sh backend.
+type FileDeleter interface {
+	DeleteFile(ctx context.Context, deleteURL string) error
+}
+
+type DeleteShortURL struct {
+	repo  repository.URLRepository
+	files FileDeleter
+}
+
+func NewDeleteShortURL(repo repository.URLRepository, files FileDeleter) *DeleteShortURL {
+	return &DeleteShortURL{repo: repo, files: files}
+}
+
+// Execute deletes the backend file and then the link itself, authorized by the
+// deletion token issued at upload time. The link is kept if the backend delete
+// fails so the request can be retried.
+func (uc *DeleteShortURL) Execute(ctx context.Context, token, deleteToken string) error {
+	if token == "" {
+		return ErrEmptyToken
+	}
+
+	shortURL, err := uc.repo.FindByToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if shortURL.DeleteToken == "" || subtle.ConstantTimeCompare([]byte(shortURL.DeleteToken), []byte(deleteToken)) != 1 {
+		return ErrInvalidDeleteToken
+	}
+
+	if err := uc.files.DeleteFile(ctx, shortURL.DeleteURL()); err != nil {
+		return fmt.Errorf("%w: %v", ErrFileDeletion, err)
+	}
+
+	return uc.repo.Delete(ctx, token)
+}
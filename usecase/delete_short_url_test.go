@@ -0,0 +1,121 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+	"transfer-shortener/usecase"
+)
+
+type mockFileDeleter struct {
+	deleteFileFunc func(ctx context.Context, deleteURL string) error
+}
+
+func (m *mockFileDeleter) DeleteFile(ctx context.Context, deleteURL string) error {
+	if m.deleteFileFunc != nil {
+		return m.deleteFileFunc(ctx, deleteURL)
+	}
+	return nil
+}
+
+func deletableRepo(deleted *string) *mockURLRepository {
+	return &mockURLRepository{
+		findByTokenFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			if token != "xyz1" {
+				return nil, repository.ErrNotFound
+			}
+			return &entity.ShortURL{
+				Token:       token,
+				FullURL:     "https://transfer.sixtyfive.me/abc12/file.txt",
+				DeleteToken: "s3cr3t",
+				CreatedAt:   time.Now(),
+			}, nil
+		},
+		deleteFunc: func(ctx context.Context, token string) error {
+			*deleted = token
+			return nil
+		},
+	}
+}
+
+func TestDeleteShortURL_Success(t *testing.T) {
+	var deletedToken, deletedFile string
+	repo := deletableRepo(&deletedToken)
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			deletedFile = deleteURL
+			return nil
+		},
+	}
+
+	uc := usecase.NewDeleteShortURL(repo, files)
+
+	err := uc.Execute(context.Background(), "xyz1", "s3cr3t")
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deletedFile != "https://transfer.sixtyfive.me/abc12/file.txt/s3cr3t" {
+		t.Errorf("expected backend delete URL, got %q", deletedFile)
+	}
+	if deletedToken != "xyz1" {
+		t.Errorf("expected token xyz1 to be deleted, got %q", deletedToken)
+	}
+}
+
+func TestDeleteShortURL_WrongDeleteToken(t *testing.T) {
+	var deletedToken string
+	repo := deletableRepo(&deletedToken)
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			t.Error("backend delete should NOT be called with a wrong deletion token")
+			return nil
+		},
+	}
+
+	uc := usecase.NewDeleteShortURL(repo, files)
+
+	err := uc.Execute(context.Background(), "xyz1", "wrong")
+
+	if !errors.Is(err, usecase.ErrInvalidDeleteToken) {
+		t.Errorf("expected ErrInvalidDeleteToken, got %v", err)
+	}
+	if deletedToken != "" {
+		t.Error("expected link to be kept")
+	}
+}
+
+func TestDeleteShortURL_NotFound(t *testing.T) {
+	var deletedToken string
+	uc := usecase.NewDeleteShortURL(deletableRepo(&deletedToken), &mockFileDeleter{})
+
+	err := uc.Execute(context.Background(), "nope", "s3cr3t")
+
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeleteShortURL_BackendFailureKeepsLink(t *testing.T) {
+	var deletedToken string
+	files := &mockFileDeleter{
+		deleteFileFunc: func(ctx context.Context, deleteURL string) error {
+			return errors.New("connection refused")
+		},
+	}
+
+	uc := usecase.NewDeleteShortURL(deletableRepo(&deletedToken), files)
+
+	err := uc.Execute(context.Background(), "xyz1", "s3cr3t")
+
+	if !errors.Is(err, usecase.ErrFileDeletion) {
+		t.Errorf("expected ErrFileDeletion, got %v", err)
+	}
+	if deletedToken != "" {
+		t.Error("expected link to be kept when backend delete fails")
+	}
+}
@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"context"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+)
+
+type GetLinkStats struct {
+	repo repository.URLRepository
+}
+
+func NewGetLinkStats(repo repository.URLRepository) *GetLinkStats {
+	return &GetLinkStats{repo: repo}
+}
+
+func (uc *GetLinkStats) Execute(ctx context.Context, token string) (*entity.LinkStats, error) {
+	if token == "" {
+		return nil, ErrEmptyToken
+	}
+	return uc.repo.Stats(ctx, token)
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"transfer-shortener/domain/repository"
+)
+
+type PurgeExpiredURLs struct {
+	repo      repository.URLRepository
+	retention time.Duration
+}
+
+// NewPurgeExpiredURLs creates a purge use case. A zero retention keeps links
+// regardless of age and only removes those past their own expiry.
+func NewPurgeExpiredURLs(repo repository.URLRepository, retention time.Duration) *PurgeExpiredURLs {
+	return &PurgeExpiredURLs{repo: repo, retention: retention}
+}
+
+func (uc *PurgeExpiredURLs) Execute(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	var createdBefore time.Time
+	if uc.retention > 0 {
+		createdBefore = now.Add(-uc.retention)
+	}
+
+	return uc.repo.DeleteExpired(ctx, createdBefore, now)
+}
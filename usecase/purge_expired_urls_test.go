@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-shortener/usecase"
+)
+
+func TestPurgeExpiredURLs_WithRetention(t *testing.T) {
+	var receivedCreatedBefore, receivedNow time.Time
+	repo := &mockURLRepository{
+		deleteExpiredFunc: func(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+			receivedCreatedBefore = createdBefore
+			receivedNow = now
+			return 3, nil
+		},
+	}
+
+	uc := usecase.NewPurgeExpiredURLs(repo, 14*24*time.Hour)
+
+	deleted, err := uc.Execute(context.Background())
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("expected 3 deleted, got %d", deleted)
+	}
+	if got := receivedNow.Sub(receivedCreatedBefore); got != 14*24*time.Hour {
+		t.Errorf("expected cutoff 14 days before now, got %v", got)
+	}
+}
+
+func TestPurgeExpiredURLs_ZeroRetentionOnlyPurgesExpired(t *testing.T) {
+	var receivedCreatedBefore time.Time
+	repo := &mockURLRepository{
+		deleteExpiredFunc: func(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+			receivedCreatedBefore = createdBefore
+			return 0, nil
+		},
+	}
+
+	uc := usecase.NewPurgeExpiredURLs(repo, 0)
+
+	if _, err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !receivedCreatedBefore.IsZero() {
+		t.Errorf("expected no age cutoff, got %v", receivedCreatedBefore)
+	}
+}
+
+func TestPurgeExpiredURLs_RepositoryError(t *testing.T) {
+	repo := &mockURLRepository{
+		deleteExpiredFunc: func(ctx context.Context, createdBefore, now time.Time) (int64, error) {
+			return 0, errors.New("database error")
+		},
+	}
+
+	uc := usecase.NewPurgeExpiredURLs(repo, time.Hour)
+
+	if _, err := uc.Execute(context.Background()); err == nil {
+		t.Error("expected error when repository fails, got nil")
+	}
+}
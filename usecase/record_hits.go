@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/domain/repository"
+)
+
+// hitFlushTimeout bounds the final flush once Run's context is cancelled.
+const hitFlushTimeout = 5 * time.Second
+
+// RecordHits buffers link hits in memory and writes them in batches, so
+// recording never delays a redirect. Hits arriving while the buffer is full
+// are dropped and counted.
+type RecordHits struct {
+	repo          repository.URLRepository
+	hits          chan entity.Hit
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64
+}
+
+func NewRecordHits(repo repository.URLRepository, bufferSize int, flushInterval time.Duration) *RecordHits {
+	bufferSize = max(bufferSize, 1)
+	return &RecordHits{
+		repo:          repo,
+		hits:          make(chan entity.Hit, bufferSize),
+		batchSize:     max(bufferSize/2, 1),
+		flushInterval: flushInterval,
+	}
+}
+
+// Record queues hit without blocking.
+func (uc *RecordHits) Record(hit entity.Hit) {
+	select {
+	case uc.hits <- hit:
+	default:
+		uc.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many hits were discarded because the buffer was full.
+func (uc *RecordHits) Dropped() int64 {
+	return uc.dropped.Load()
+}
+
+// Run writes queued hits every flushInterval, or sooner once a batch fills,
+// until ctx is cancelled. Hits still queued then are flushed before it returns.
+func (uc *RecordHits) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]entity.Hit, 0, uc.batchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		if err := uc.repo.RecordHits(ctx, batch); err != nil {
+			log.Printf("hits: dropped %d hits: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case hit := <-uc.hits:
+			batch = append(batch, hit)
+			if len(batch) >= uc.batchSize {
+				flush(ctx)
+			}
+		case <-ticker.C:
+			flush(ctx)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), hitFlushTimeout)
+			defer cancel()
+			for {
+				select {
+				case hit := <-uc.hits:
+					batch = append(batch, hit)
+				default:
+					flush(flushCtx)
+					return
+				}
+			}
+		}
+	}
+}
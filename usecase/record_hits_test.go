@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/usecase"
+)
+
+func TestRecordHits_FlushesOnShutdown(t *testing.T) {
+	var mu sync.Mutex
+	var recorded []entity.Hit
+	repo := &mockURLRepository{
+		recordHitsFunc: func(ctx context.Context, hits []entity.Hit) error {
+			mu.Lock()
+			defer mu.Unlock()
+			recorded = append(recorded, hits...)
+			return nil
+		},
+	}
+
+	uc := usecase.NewRecordHits(repo, 100, time.Hour)
+	uc.Record(entity.Hit{Token: "abc1"})
+	uc.Record(entity.Hit{Token: "abc2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uc.Run(ctx)
+
+	if len(recorded) != 2 {
+		t.Fatalf("expected 2 hits flushed, got %d", len(recorded))
+	}
+	if recorded[0].Token != "abc1" || recorded[1].Token != "abc2" {
+		t.Errorf("expected hits in order, got %v", recorded)
+	}
+}
+
+func TestRecordHits_FlushesFullBatch(t *testing.T) {
+	flushed := make(chan []entity.Hit, 1)
+	repo := &mockURLRepository{
+		recordHitsFunc: func(ctx context.Context, hits []entity.Hit) error {
+			flushed <- append([]entity.Hit(nil), hits...)
+			return nil
+		},
+	}
+
+	uc := usecase.NewRecordHits(repo, 4, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go uc.Run(ctx)
+
+	uc.Record(entity.Hit{Token: "abc1"})
+	uc.Record(entity.Hit{Token: "abc2"})
+
+	select {
+	case hits := <-flushed:
+		if len(hits) != 2 {
+			t.Errorf("expected a batch of 2, got %d", len(hits))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected full batch to be flushed before the interval")
+	}
+}
+
+func TestRecordHits_DropsWhenBufferFull(t *testing.T) {
+	uc := usecase.NewRecordHits(&mockURLRepository{}, 1, time.Hour)
+
+	uc.Record(entity.Hit{Token: "abc1"})
+	uc.Record(entity.Hit{Token: "abc2"})
+
+	if got := uc.Dropped(); got != 1 {
+		t.Errorf("expected 1 dropped hit, got %d", got)
+	}
+}
+
+func TestGetLinkStats_EmptyToken(t *testing.T) {
+	uc := usecase.NewGetLinkStats(&mockURLRepository{})
+
+	if _, err := uc.Execute(context.Background(), ""); err != usecase.ErrEmptyToken {
+		t.Errorf("expected ErrEmptyToken, got %v", err)
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 
+	"transfer-shortener/domain/entity"
 	"transfer-shortener/domain/repository"
 )
 
-var ErrEmptyToken = errors.New("token cannot be empty")
+var (
+	ErrEmptyToken = errors.New("token cannot be empty")
+	ErrExpired    = errors.New("short URL has expired")
+)
 
 type ResolveShortURL struct {
 	repo repository.URLRepository
@@ -17,15 +21,19 @@ func NewResolveShortURL(repo repository.URLRepository) *ResolveShortURL {
 	return &ResolveShortURL{repo: repo}
 }
 
-func (uc *ResolveShortURL) Execute(ctx context.Context, token string) (string, error) {
+func (uc *ResolveShortURL) Execute(ctx context.Context, token string) (*entity.ShortURL, error) {
 	if token == "" {
-		return "", ErrEmptyToken
+		return nil, ErrEmptyToken
 	}
 
 	shortURL, err := uc.repo.FindByToken(ctx, token)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	if shortURL.HasExpired() || shortURL.DownloadsExhausted() {
+		return nil, ErrExpired
 	}
 
-	return shortURL.FullURL, nil
+	return shortURL, nil
 }
@@ -29,8 +29,8 @@ func TestResolveShortURL_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if result != expectedURL {
-		t.Errorf("expected %s, got %s", expectedURL, result)
+	if result.FullURL != expectedURL {
+		t.Errorf("expected %s, got %s", expectedURL, result.FullURL)
 	}
 }
 
@@ -60,3 +60,45 @@ func TestResolveShortURL_EmptyToken(t *testing.T) {
 		t.Error("expected error for empty token, got nil")
 	}
 }
+
+func TestResolveShortURL_Expired(t *testing.T) {
+	repo := &mockURLRepository{
+		findByTokenFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{
+				Token:     token,
+				FullURL:   "https://transfer.sixtyfive.me/abc12/file.txt",
+				CreatedAt: time.Now().Add(-48 * time.Hour),
+				ExpiresAt: time.Now().Add(-24 * time.Hour),
+			}, nil
+		},
+	}
+
+	uc := usecase.NewResolveShortURL(repo)
+
+	_, err := uc.Execute(context.Background(), "abc1")
+
+	if !errors.Is(err, usecase.ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestResolveShortURL_DownloadsExhausted(t *testing.T) {
+	repo := &mockURLRepository{
+		findByTokenFunc: func(ctx context.Context, token string) (*entity.ShortURL, error) {
+			return &entity.ShortURL{
+				Token:        token,
+				FullURL:      "https://transfer.sixtyfive.me/abc12/file.txt",
+				MaxDownloads: 1,
+				Downloads:    1,
+			}, nil
+		},
+	}
+
+	uc := usecase.NewResolveShortURL(repo)
+
+	_, err := uc.Execute(context.Background(), "abc1")
+
+	if !errors.Is(err, usecase.ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+}
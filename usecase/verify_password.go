@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"transfer-shortener/domain/entity"
+)
+
+var (
+	ErrPasswordRequired = errors.New("password required")
+	ErrWrongPassword    = errors.New("wrong password")
+	ErrTooManyAttempts  = errors.New("too many wrong passwords, try again later")
+)
+
+// VerifyPassword checks passwords for protected links and throttles guessing:
+// once a token sees maxFailures wrong passwords within window, every attempt
+// is refused until the window has passed. Failures are counted in memory, so
+// every replica keeps its own count.
+type VerifyPassword struct {
+	maxFailures int
+	window      time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*passwordFailures
+}
+
+type passwordFailures struct {
+	count int
+	since time.Time
+}
+
+func NewVerifyPassword(maxFailures int, window time.Duration) *VerifyPassword {
+	return &VerifyPassword{
+		maxFailures: maxFailures,
+		window:      window,
+		failures:    make(map[string]*passwordFailures),
+	}
+}
+
+func (uc *VerifyPassword) Execute(ctx context.Context, shortURL *entity.ShortURL, password string) error {
+	if !shortURL.HasPassword() {
+		return nil
+	}
+	if password == "" {
+		return ErrPasswordRequired
+	}
+
+	now := time.Now()
+	if uc.throttled(shortURL.Token, now) {
+		return ErrTooManyAttempts
+	}
+	if !shortURL.CheckPassword(password) {
+		uc.fail(shortURL.Token, now)
+		return ErrWrongPassword
+	}
+	return nil
+}
+
+func (uc *VerifyPassword) throttled(token string, now time.Time) bool {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	f, ok := uc.failures[token]
+	if !ok {
+		return false
+	}
+	if now.Sub(f.since) >= uc.window {
+		delete(uc.failures, token)
+		return false
+	}
+	return f.count >= uc.maxFailures
+}
+
+func (uc *VerifyPassword) fail(token string, now time.Time) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// Forget windows that have passed so guesses against many tokens do not
+	// grow the map without bound.
+	for t, f := range uc.failures {
+		if now.Sub(f.since) >= uc.window {
+			delete(uc.failures, t)
+		}
+	}
+
+	f, ok := uc.failures[token]
+	if !ok {
+		f = &passwordFailures{since: now}
+		uc.failures[token] = f
+	}
+	f.count++
+}
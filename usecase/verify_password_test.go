@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-shortener/domain/entity"
+	"transfer-shortener/usecase"
+)
+
+func protectedURL(t *testing.T, token, password string) *entity.ShortURL {
+	t.Helper()
+	shortURL := &entity.ShortURL{Token: token, FullURL: "https://transfer.sixtyfive.me/abc12/file.txt"}
+	if err := shortURL.SetPassword(password); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return shortURL
+}
+
+func TestVerifyPassword(t *testing.T) {
+	shortURL := protectedURL(t, "abc1", "s3cr3t")
+
+	tests := []struct {
+		name     string
+		shortURL *entity.ShortURL
+		password string
+		expected error
+	}{
+		{"open link", &entity.ShortURL{Token: "open"}, "", nil},
+		{"missing password", shortURL, "", usecase.ErrPasswordRequired},
+		{"wrong password", shortURL, "guess", usecase.ErrWrongPassword},
+		{"correct password", shortURL, "s3cr3t", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := usecase.NewVerifyPassword(5, time.Minute)
+
+			err := uc.Execute(context.Background(), tt.shortURL, tt.password)
+
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPassword_ThrottlesPerToken(t *testing.T) {
+	uc := usecase.NewVerifyPassword(2, time.Minute)
+	target := protectedURL(t, "abc1", "s3cr3t")
+	other := protectedURL(t, "abc2", "s3cr3t")
+
+	for range 2 {
+		if err := uc.Execute(context.Background(), target, "guess"); !errors.Is(err, usecase.ErrWrongPassword) {
+			t.Fatalf("expected ErrWrongPassword, got %v", err)
+		}
+	}
+
+	if err := uc.Execute(context.Background(), target, "s3cr3t"); !errors.Is(err, usecase.ErrTooManyAttempts) {
+		t.Errorf("expected ErrTooManyAttempts even for the right password, got %v", err)
+	}
+	if err := uc.Execute(context.Background(), other, "s3cr3t"); err != nil {
+		t.Errorf("expected other tokens to be unaffected, got %v", err)
+	}
+}
+
+func TestVerifyPassword_WindowExpires(t *testing.T) {
+	uc := usecase.NewVerifyPassword(1, 10*time.Millisecond)
+	shortURL := protectedURL(t, "abc1", "s3cr3t")
+
+	uc.Execute(context.Background(), shortURL, "guess")
+	time.Sleep(20 * time.Millisecond)
+
+	if err := uc.Execute(context.Background(), shortURL, "s3cr3t"); err != nil {
+		t.Errorf("expected attempts to be allowed after the window, got %v", err)
+	}
+}